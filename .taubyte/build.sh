@@ -4,6 +4,12 @@ set -x
 
 . /utils/wasm.sh
 
+# Snake config from the variables in .taubyte/config.yaml, laid over
+# config/snakes.json
+if [ -n "${SNAKE_CONFIG}" ]; then
+    echo "${SNAKE_CONFIG}" > config/override.json
+fi
+
 echo "Building ${FILENAME}"
 
 build debug "${FILENAME}"
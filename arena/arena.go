@@ -0,0 +1,272 @@
+package arena
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+	v6 "github.com/samyfodil/tb_library_snake_001/v6"
+
+	// Strategies register themselves with the strategy package
+	_ "github.com/samyfodil/tb_library_snake_001/ensemble"
+	_ "github.com/samyfodil/tb_library_snake_001/v1"
+	_ "github.com/samyfodil/tb_library_snake_001/v2"
+	_ "github.com/samyfodil/tb_library_snake_001/v3"
+	_ "github.com/samyfodil/tb_library_snake_001/v4"
+	_ "github.com/samyfodil/tb_library_snake_001/v5"
+)
+
+type Config struct {
+	Width    int
+	Height   int
+	Snakes   []string // Strategy name of every snake in the game
+	Seed     int64
+	Ruleset  string
+	Settings types.RulesetSettings
+	MaxTurns int
+	Timeout  int
+
+	// Strategy a snake alone on the board switches to, as the server does
+	// with its solo strategy. When empty every snake keeps its own.
+	Solo string
+
+	// Per-turn log, nothing is written when nil
+	Log io.Writer
+}
+
+type SnakeResult struct {
+	ID       string
+	Name     string
+	Turns    int // Number of turns survived
+	Length   int
+	Cause    rules.EliminationCause
+	Panicked int // Number of moves that panicked
+	TimedOut int // Number of moves that took longer than the timeout
+}
+
+type Result struct {
+	Winner string // Snake ID, empty on a draw
+	Turns  int
+	Snakes []SnakeResult
+}
+
+// Play runs a full game between the configured strategies, entirely offline
+func Play(config Config) (Result, error) {
+	for _, name := range config.Snakes {
+		if _, ok := strategy.Lookup(name); !ok {
+			return Result{}, fmt.Errorf("unknown strategy %q", name)
+		}
+	}
+	if _, ok := strategy.Lookup(config.Solo); config.Solo != "" && !ok {
+		return Result{}, fmt.Errorf("unknown solo strategy %q", config.Solo)
+	}
+
+	if config.MaxTurns <= 0 {
+		config.MaxTurns = 1000
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 500
+	}
+
+	rng := rand.New(rand.NewSource(config.Seed))
+	state, err := newGame(config, rng)
+	if err != nil {
+		return Result{}, err
+	}
+
+	results := make(map[string]*SnakeResult, len(config.Snakes))
+	for _, snake := range state.Board.Snakes {
+		results[snake.ID] = &SnakeResult{ID: snake.ID, Name: snake.Name, Length: len(snake.Body)}
+	}
+
+	// A lone snake only plays until it dies, everyone else until one is left
+	lastStanding := 1
+	if len(config.Snakes) == 1 {
+		lastStanding = 0
+	}
+
+	for len(state.Board.Snakes) > lastStanding && state.Turn < config.MaxTurns {
+		moves := make(map[string]types.Direction, len(state.Board.Snakes))
+		for _, snake := range state.Board.Snakes {
+			move, err := askMove(state, snake, config.Solo, time.Duration(config.Timeout)*time.Millisecond)
+			switch err {
+			case errPanicked:
+				results[snake.ID].Panicked++
+			case errTimedOut:
+				results[snake.ID].TimedOut++
+			}
+			moves[snake.ID] = move
+		}
+
+		outcome := rules.Next(state, moves, rng)
+		logTurn(config.Log, state, moves, outcome)
+
+		for _, elimination := range outcome.Eliminated {
+			results[elimination.SnakeID].Cause = elimination.Cause
+		}
+		state = outcome.State
+
+		for _, snake := range state.Board.Snakes {
+			results[snake.ID].Turns = state.Turn
+			results[snake.ID].Length = len(snake.Body)
+		}
+	}
+
+	result := Result{Turns: state.Turn}
+	if len(state.Board.Snakes) == 1 && len(config.Snakes) > 1 {
+		result.Winner = state.Board.Snakes[0].ID
+	}
+
+	for i := range config.Snakes {
+		result.Snakes = append(result.Snakes, *results[snakeID(i)])
+	}
+
+	return result, nil
+}
+
+func snakeID(i int) string {
+	return fmt.Sprintf("snake-%d", i+1)
+}
+
+func newGame(config Config, rng *rand.Rand) (*types.GameState, error) {
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, fmt.Errorf("invalid board size %dx%d", config.Width, config.Height)
+	}
+	if len(config.Snakes) == 0 {
+		return nil, fmt.Errorf("no snakes")
+	}
+
+	cells := make([]types.Coord, 0, config.Width*config.Height)
+	for y := 0; y < config.Height; y++ {
+		for x := 0; x < config.Width; x++ {
+			cells = append(cells, types.Coord{X: x, Y: y})
+		}
+	}
+	if len(cells) < 2*len(config.Snakes) {
+		return nil, fmt.Errorf("board too small for %d snakes", len(config.Snakes))
+	}
+	rng.Shuffle(len(cells), func(i, j int) { cells[i], cells[j] = cells[j], cells[i] })
+
+	ruleset := config.Ruleset
+	if ruleset == "" {
+		ruleset = rules.Standard
+	}
+
+	state := &types.GameState{
+		Game: types.Game{
+			ID: fmt.Sprintf("arena-%d", config.Seed),
+			Ruleset: types.Ruleset{
+				Name:     ruleset,
+				Version:  "arena",
+				Settings: config.Settings,
+			},
+			Source:  "arena",
+			Timeout: config.Timeout,
+		},
+		Board: types.Board{
+			Width:  config.Width,
+			Height: config.Height,
+		},
+	}
+
+	// Snakes start stacked on a single cell, like in the real engine, with
+	// one food each placed on another random cell
+	for i, name := range config.Snakes {
+		start := cells[i]
+		state.Board.Snakes = append(state.Board.Snakes, types.Battlesnake{
+			ID:     snakeID(i),
+			Name:   name,
+			Health: rules.MaxHealth,
+			Body:   []types.Coord{start, start, start},
+			Head:   start,
+			Length: 3,
+		})
+	}
+
+	if ruleset != rules.Constrictor {
+		for i := range config.Snakes {
+			state.Board.Food = append(state.Board.Food, cells[len(config.Snakes)+i])
+		}
+	}
+
+	return state, nil
+}
+
+var (
+	errPanicked = errors.New("strategy panicked")
+	errTimedOut = errors.New("strategy timed out")
+)
+
+// askMove gets the move of a single snake from its strategy, or from the solo
+// strategy when one is given and the snake is alone. Each strategy gets its
+// own copy of the state since some of them write to it. Like in the real
+// engine, a strategy that panics, misses the timeout or answers with no valid
+// move keeps going straight.
+func askMove(state *types.GameState, snake types.Battlesnake, solo string, timeout time.Duration) (types.Direction, error) {
+	view := rules.Clone(state)
+	view.You = snake
+	view.You.Body = append([]types.Coord(nil), snake.Body...)
+
+	// Strategies are told to be done a little before the timeout, the time
+	// the network would take in a real game
+	ctx, cancel := context.WithTimeout(context.Background(), timeout-timeout/10)
+	defer cancel()
+
+	done := make(chan string, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				close(done)
+			}
+		}()
+
+		name := snake.Name
+		if solo != "" && v6.IsSolo(view) {
+			name = solo
+		}
+		s, _ := strategy.Lookup(name)
+		done <- s.Move(ctx, view).Move
+	}()
+
+	select {
+	case move, ok := <-done:
+		if !ok {
+			return types.NoDirection, errPanicked
+		}
+		direction, _ := types.ParseDirection(move)
+		return direction, nil
+	case <-time.After(timeout):
+		return types.NoDirection, errTimedOut
+	}
+}
+
+func logTurn(w io.Writer, state *types.GameState, moves map[string]types.Direction, outcome rules.Result) {
+	if w == nil {
+		return
+	}
+
+	parts := make([]string, 0, len(state.Board.Snakes))
+	for _, snake := range state.Board.Snakes {
+		move := moves[snake.ID].String()
+		if move == "" {
+			move = "?"
+		}
+		parts = append(parts, fmt.Sprintf("%s(%s) %s h=%d l=%d", snake.ID, snake.Name, move, snake.Health, len(snake.Body)))
+	}
+	fmt.Fprintf(w, "turn %d: %s\n", state.Turn, strings.Join(parts, ", "))
+
+	for _, elimination := range outcome.Eliminated {
+		if elimination.By != "" && elimination.By != elimination.SnakeID {
+			fmt.Fprintf(w, "  %s eliminated: %s by %s\n", elimination.SnakeID, elimination.Cause, elimination.By)
+		} else {
+			fmt.Fprintf(w, "  %s eliminated: %s\n", elimination.SnakeID, elimination.Cause)
+		}
+	}
+}
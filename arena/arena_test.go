@@ -0,0 +1,70 @@
+package arena
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+func init() {
+	strategy.Register(strategy.Func{StrategyName: "test-left", MoveFunc: func(*types.GameState) types.BattlesnakeMoveResponse {
+		return types.BattlesnakeMoveResponse{Move: types.Left.String()}
+	}})
+}
+
+// Helper functions
+
+// aloneState has a snake alone in the middle of the board, heading down
+func aloneState(ruleset string) *types.GameState {
+	snake := types.Battlesnake{
+		ID:     "gs_0",
+		Name:   "test-left",
+		Health: 100,
+		Body:   []types.Coord{{X: 5, Y: 5}, {X: 5, Y: 6}, {X: 5, Y: 7}},
+		Head:   types.Coord{X: 5, Y: 5},
+		Length: 3,
+	}
+	return &types.GameState{
+		Game:  types.Game{Ruleset: types.Ruleset{Name: ruleset}},
+		Board: types.Board{Width: 11, Height: 11, Snakes: []types.Battlesnake{snake}},
+		You:   snake,
+	}
+}
+
+// Tests
+
+func TestAskMoveSolo(t *testing.T) {
+	tests := []struct {
+		name    string
+		ruleset string
+		solo    string
+		want    types.Direction
+	}{
+		{name: "own strategy in a solo game", ruleset: rules.Solo, want: types.Left},
+		{name: "own strategy when the last one standing", ruleset: rules.Standard, want: types.Left},
+		// v6 follows its cycle, which goes down column 5
+		{name: "solo strategy", ruleset: rules.Solo, solo: "v6", want: types.Down},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := aloneState(test.ruleset)
+			move, err := askMove(state, state.Board.Snakes[0], test.solo, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if move != test.want {
+				t.Errorf("moved %s, want %s", move, test.want)
+			}
+		})
+	}
+}
+
+func TestPlayUnknownSolo(t *testing.T) {
+	if _, err := Play(Config{Snakes: []string{"test-left"}, Solo: "no-such-strategy"}); err == nil {
+		t.Error("got no error")
+	}
+}
@@ -0,0 +1,199 @@
+package arena
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+)
+
+const (
+	InitialElo = 1500
+	EloK       = 32
+)
+
+type TournamentConfig struct {
+	Game Config // Template for every game, Snakes and Seed are filled in
+
+	Strategies []string
+
+	// Number of games played by every pair of strategies
+	DuelGames int
+
+	// Number of free-for-all games, each between FreeForAllSize randomly
+	// drawn strategies
+	FreeForAllGames int
+	FreeForAllSize  int
+}
+
+type Standing struct {
+	Strategy  string  `json:"strategy"`
+	Games     int     `json:"games"`
+	Wins      int     `json:"wins"`
+	WinRate   float64 `json:"winRate"`
+	AvgTurns  float64 `json:"avgTurns"`
+	AvgLength float64 `json:"avgLength"`
+	Elo       float64 `json:"elo"`
+
+	totalTurns  int
+	totalLength int
+}
+
+type Report struct {
+	Games     int        `json:"games"`
+	Standings []Standing `json:"standings"`
+}
+
+// RunTournament plays every pair of strategies against each other, then the
+// free-for-all games, with seeds derived from config.Game.Seed so that the
+// same configuration always gives the same report.
+func RunTournament(config TournamentConfig) (Report, error) {
+	if len(config.Strategies) == 0 {
+		config.Strategies = strategy.Names()
+	}
+	if config.FreeForAllSize <= 0 {
+		config.FreeForAllSize = 4
+	}
+
+	standings := make(map[string]*Standing, len(config.Strategies))
+	for _, name := range config.Strategies {
+		if _, ok := strategy.Lookup(name); !ok {
+			return Report{}, fmt.Errorf("unknown strategy %q", name)
+		}
+		standings[name] = &Standing{Strategy: name, Elo: InitialElo}
+	}
+
+	seed := config.Game.Seed
+	games := 0
+
+	play := func(snakes []string) error {
+		game := config.Game
+		game.Snakes = snakes
+		game.Seed = seed
+		seed++
+
+		result, err := Play(game)
+		if err != nil {
+			return err
+		}
+
+		record(standings, result)
+		games++
+		return nil
+	}
+
+	for i := range config.Strategies {
+		for j := i + 1; j < len(config.Strategies); j++ {
+			for g := 0; g < config.DuelGames; g++ {
+				// Alternate who gets the first starting cell
+				snakes := []string{config.Strategies[i], config.Strategies[j]}
+				if g%2 == 1 {
+					snakes[0], snakes[1] = snakes[1], snakes[0]
+				}
+				if err := play(snakes); err != nil {
+					return Report{}, err
+				}
+			}
+		}
+	}
+
+	size := config.FreeForAllSize
+	if size > len(config.Strategies) {
+		size = len(config.Strategies)
+	}
+
+	draw := rand.New(rand.NewSource(config.Game.Seed))
+	for g := 0; g < config.FreeForAllGames && size > 1; g++ {
+		order := draw.Perm(len(config.Strategies))
+		snakes := make([]string, size)
+		for i := range snakes {
+			snakes[i] = config.Strategies[order[i]]
+		}
+		if err := play(snakes); err != nil {
+			return Report{}, err
+		}
+	}
+
+	report := Report{Games: games}
+	for _, name := range config.Strategies {
+		standing := standings[name]
+		if standing.Games > 0 {
+			standing.WinRate = float64(standing.Wins) / float64(standing.Games)
+			standing.AvgTurns = float64(standing.totalTurns) / float64(standing.Games)
+			standing.AvgLength = float64(standing.totalLength) / float64(standing.Games)
+		}
+		report.Standings = append(report.Standings, *standing)
+	}
+
+	sort.SliceStable(report.Standings, func(i, j int) bool {
+		return report.Standings[i].Elo > report.Standings[j].Elo
+	})
+
+	return report, nil
+}
+
+// record adds a game to the standings. Multiplayer games are scored for Elo
+// as a duel between every pair of snakes, the one that survived longer
+// winning it, with K shared between the opponents.
+func record(standings map[string]*Standing, result Result) {
+	for _, snake := range result.Snakes {
+		standing := standings[snake.Name]
+		standing.Games++
+		standing.totalTurns += snake.Turns
+		standing.totalLength += snake.Length
+		if snake.ID == result.Winner {
+			standing.Wins++
+		}
+	}
+
+	if len(result.Snakes) < 2 {
+		return
+	}
+
+	k := EloK / float64(len(result.Snakes)-1)
+	deltas := make([]float64, len(result.Snakes))
+
+	for i, a := range result.Snakes {
+		for j := i + 1; j < len(result.Snakes); j++ {
+			b := result.Snakes[j]
+			if a.Name == b.Name {
+				continue
+			}
+
+			score := 0.5
+			switch {
+			case a.ID == result.Winner || a.Turns > b.Turns:
+				score = 1
+			case b.ID == result.Winner || b.Turns > a.Turns:
+				score = 0
+			}
+
+			expected := 1 / (1 + math.Pow(10, (standings[b.Name].Elo-standings[a.Name].Elo)/400))
+			deltas[i] += k * (score - expected)
+			deltas[j] -= k * (score - expected)
+		}
+	}
+
+	// Ratings change only once the whole game is scored
+	for i, snake := range result.Snakes {
+		standings[snake.Name].Elo += deltas[i]
+	}
+}
+
+func (r Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "%d games\n", r.Games)
+	fmt.Fprintf(w, "%-10s %6s %6s %8s %10s %10s %8s\n", "strategy", "games", "wins", "win rate", "avg turns", "avg length", "elo")
+	for _, s := range r.Standings {
+		fmt.Fprintf(w, "%-10s %6d %6d %7.1f%% %10.1f %10.1f %8.0f\n", s.Strategy, s.Games, s.Wins, 100*s.WinRate, s.AvgTurns, s.AvgLength, s.Elo)
+	}
+}
+
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
@@ -0,0 +1,119 @@
+package arena
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRecordElo(t *testing.T) {
+	type player struct {
+		name  string
+		elo   float64
+		turns int
+	}
+
+	tests := []struct {
+		name    string
+		players []player
+		winner  string // Name of the winner, empty on a draw
+		want    map[string]float64
+	}{
+		{
+			name:    "even duel",
+			players: []player{{name: "a", elo: 1500, turns: 50}, {name: "b", elo: 1500, turns: 49}},
+			winner:  "a",
+			want:    map[string]float64{"a": 1516, "b": 1484},
+		},
+		{
+			name:    "even draw",
+			players: []player{{name: "a", elo: 1500, turns: 50}, {name: "b", elo: 1500, turns: 50}},
+			want:    map[string]float64{"a": 1500, "b": 1500},
+		},
+		{
+			// The favourite was expected to score 0.76, a draw costs it
+			// 32 * (0.5 - 0.76)
+			name:    "draw against a weaker snake",
+			players: []player{{name: "a", elo: 1600, turns: 50}, {name: "b", elo: 1400, turns: 50}},
+			want:    map[string]float64{"a": 1591.688, "b": 1408.312},
+		},
+		{
+			name:    "upset",
+			players: []player{{name: "a", elo: 1600, turns: 49}, {name: "b", elo: 1400, turns: 50}},
+			winner:  "b",
+			want:    map[string]float64{"a": 1575.688, "b": 1424.312},
+		},
+		{
+			// K is split between two opponents: the winner takes 8 off each
+			name: "free for all",
+			players: []player{
+				{name: "a", elo: 1500, turns: 30},
+				{name: "b", elo: 1500, turns: 20},
+				{name: "c", elo: 1500, turns: 10},
+			},
+			winner: "a",
+			want:   map[string]float64{"a": 1516, "b": 1500, "c": 1484},
+		},
+		{
+			name:    "same strategy",
+			players: []player{{name: "a", elo: 1500, turns: 50}, {name: "a", elo: 1500, turns: 10}},
+			winner:  "a",
+			want:    map[string]float64{"a": 1500},
+		},
+		{
+			name:    "alone",
+			players: []player{{name: "a", elo: 1500, turns: 50}},
+			want:    map[string]float64{"a": 1500},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			standings := make(map[string]*Standing)
+			result := Result{Turns: 50}
+			for i, p := range test.players {
+				standings[p.name] = &Standing{Strategy: p.name, Elo: p.elo}
+				id := string(rune('0' + i))
+				result.Snakes = append(result.Snakes, SnakeResult{ID: id, Name: p.name, Turns: p.turns})
+				if p.name == test.winner && result.Winner == "" {
+					result.Winner = id
+				}
+			}
+
+			record(standings, result)
+
+			for name, want := range test.want {
+				if got := standings[name].Elo; math.Abs(got-want) > 0.001 {
+					t.Errorf("%s: elo %.3f, want %.3f", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordStandings(t *testing.T) {
+	standings := map[string]*Standing{
+		"a": {Strategy: "a", Elo: InitialElo},
+		"b": {Strategy: "b", Elo: InitialElo},
+	}
+	record(standings, Result{Winner: "1", Turns: 40, Snakes: []SnakeResult{
+		{ID: "1", Name: "a", Turns: 40, Length: 9},
+		{ID: "2", Name: "b", Turns: 39, Length: 5},
+	}})
+	record(standings, Result{Turns: 20, Snakes: []SnakeResult{
+		{ID: "1", Name: "a", Turns: 20, Length: 4},
+		{ID: "2", Name: "b", Turns: 20, Length: 6},
+	}})
+
+	a, b := standings["a"], standings["b"]
+	if a.Games != 2 || a.Wins != 1 || b.Games != 2 || b.Wins != 0 {
+		t.Errorf("a %d of %d games, b %d of %d, want 1 of 2 and 0 of 2", a.Wins, a.Games, b.Wins, b.Games)
+	}
+	if a.totalTurns != 60 || a.totalLength != 13 || b.totalTurns != 59 || b.totalLength != 11 {
+		t.Errorf("totals a %d turns %d length, b %d turns %d length", a.totalTurns, a.totalLength, b.totalTurns, b.totalLength)
+	}
+	// Rated higher after the win, a is expected to win the draw and loses a
+	// little of what it gained
+	if a.Elo <= InitialElo || a.Elo >= InitialElo+EloK/2 || math.Abs(a.Elo+b.Elo-2*InitialElo) > 0.001 {
+		t.Errorf("elo a %.3f b %.3f", a.Elo, b.Elo)
+	}
+}
@@ -0,0 +1,142 @@
+//go:build !wasi
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/samyfodil/tb_library_snake_001/arena"
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+func main() {
+	width := flag.Int("width", 11, "board width")
+	height := flag.Int("height", 11, "board height")
+	snakes := flag.String("snakes", "tau006,tau008", "comma separated strategies, one per snake")
+	count := flag.Int("count", 0, "number of snakes, cycling through -snakes (default: one per strategy)")
+	seed := flag.Int64("seed", 1, "random seed for the board and food spawns")
+	ruleset := flag.String("ruleset", rules.Standard, "standard, solo, wrapped, constrictor or royale")
+	maxTurns := flag.Int("max-turns", 1000, "stop the game after this many turns")
+	timeout := flag.Int("timeout", 500, "milliseconds a strategy gets to answer each move")
+	solo := flag.String("solo", "", "strategy a snake alone on the board switches to, like the server (default: none)")
+	foodChance := flag.Int("food-chance", 15, "percent chance of spawning food every turn")
+	minFood := flag.Int("min-food", 1, "minimum amount of food on the board")
+	hazardDamage := flag.Int("hazard-damage", 14, "health lost per turn in a hazard")
+	shrinkEvery := flag.Int("shrink-every", 25, "royale: turns between hazard shrinks")
+	quiet := flag.Bool("quiet", false, "only print the result")
+	list := flag.Bool("list", false, "list the available strategies and exit")
+
+	tournament := flag.Bool("tournament", false, "run a round-robin tournament instead of a single game")
+	strategies := flag.String("strategies", "", "tournament: comma separated strategies (default: all)")
+	duels := flag.Int("duels", 10, "tournament: 1v1 games per pair of strategies")
+	ffa := flag.Int("ffa", 20, "tournament: number of free-for-all games")
+	ffaSize := flag.Int("ffa-size", 4, "tournament: snakes per free-for-all game")
+	asJSON := flag.Bool("json", false, "tournament: print the report as JSON")
+	flag.Parse()
+
+	if *list {
+		fmt.Println(strings.Join(strategy.Names(), "\n"))
+		return
+	}
+
+	game := arena.Config{
+		Width:   *width,
+		Height:  *height,
+		Seed:    *seed,
+		Ruleset: *ruleset,
+		Settings: types.RulesetSettings{
+			FoodSpawnChance:     *foodChance,
+			MinimumFood:         *minFood,
+			HazardDamagePerTurn: *hazardDamage,
+			Royale: types.RoyaleSettings{
+				ShrinkEveryNTurns: *shrinkEvery,
+			},
+		},
+		MaxTurns: *maxTurns,
+		Timeout:  *timeout,
+		Solo:     *solo,
+	}
+
+	var err error
+	if *tournament {
+		config := arena.TournamentConfig{
+			Game:            game,
+			DuelGames:       *duels,
+			FreeForAllGames: *ffa,
+			FreeForAllSize:  *ffaSize,
+		}
+		if *strategies != "" {
+			config.Strategies = strings.Split(*strategies, ",")
+		}
+		err = playTournament(config, *asJSON)
+	} else {
+		game.Snakes = strings.Split(*snakes, ",")
+		if *count > 0 {
+			cycled := make([]string, *count)
+			for i := range cycled {
+				cycled[i] = game.Snakes[i%len(game.Snakes)]
+			}
+			game.Snakes = cycled
+		}
+		if !*quiet {
+			game.Log = os.Stdout
+		}
+		err = playGame(game)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func playGame(config arena.Config) error {
+	result, err := arena.Play(config)
+	if err != nil {
+		return err
+	}
+
+	for _, snake := range result.Snakes {
+		cause := string(snake.Cause)
+		if cause == "" {
+			cause = "alive"
+		}
+		fmt.Printf("%s %-8s turns=%-4d length=%-3d %s", snake.ID, snake.Name, snake.Turns, snake.Length, cause)
+		if snake.Panicked > 0 {
+			fmt.Printf(" panics=%d", snake.Panicked)
+		}
+		if snake.TimedOut > 0 {
+			fmt.Printf(" timeouts=%d", snake.TimedOut)
+		}
+		fmt.Println()
+	}
+
+	if result.Winner == "" {
+		fmt.Printf("no winner after %d turns\n", result.Turns)
+	} else {
+		fmt.Printf("winner: %s after %d turns\n", result.Winner, result.Turns)
+	}
+
+	return nil
+}
+
+func playTournament(config arena.TournamentConfig, asJSON bool) error {
+	report, err := arena.RunTournament(config)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if asJSON {
+		return report.WriteJSON(out)
+	}
+
+	report.WriteText(out)
+	return nil
+}
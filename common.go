@@ -7,9 +7,9 @@ import (
 func info() types.BattlesnakeInfoResponse {
 	return types.BattlesnakeInfoResponse{
 		APIVersion: "1",
-		Author:     "",        // TODO: Your types.Battlesnake username
-		Color:      "#099a40", // TODO: Choose color
-		Head:       "fang",    // TODO: Choose head
-		Tail:       "bolt",    // TODO: Choose tail
+		Author:     "samyfodil",
+		Color:      "#099a40",
+		Head:       "fang",
+		Tail:       "bolt",
 	}
 }
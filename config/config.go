@@ -0,0 +1,165 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Environment variable holding a config laid over the embedded ones. The
+// Taubyte build has it in the environment, from the variables in
+// .taubyte/config.yaml, and writes it to override.json.
+const EnvVar = "SNAKE_CONFIG"
+
+//go:embed snakes.json
+var embedded []byte
+
+//go:embed override.json
+var override []byte
+
+// Rule maps snakes to a strategy. Every field of the match that is set has
+// to match: the snake's name, which is also the snake route it is served
+// on, the snake's ID and the source of the game.
+type Rule struct {
+	Name   string `json:"name,omitempty"`
+	ID     string `json:"id,omitempty"`
+	Source string `json:"source,omitempty"`
+
+	Strategy string          `json:"strategy"`
+	Params   strategy.Params `json:"params,omitempty"`
+}
+
+// Matches reports whether the rule applies to our snake in state, served on
+// the snake route key
+func (r Rule) Matches(key string, state *types.GameState) bool {
+	if r.Name == "" && r.ID == "" && r.Source == "" {
+		return false
+	}
+	if r.Name != "" && r.Name != state.You.Name && r.Name != key {
+		return false
+	}
+	if r.ID != "" && r.ID != state.You.ID {
+		return false
+	}
+	if r.Source != "" && r.Source != state.Game.Source {
+		return false
+	}
+	return true
+}
+
+type Config struct {
+	// Strategy for snakes no rule matches
+	Default string `json:"default,omitempty"`
+
+	// Strategy for games with nobody else on the board
+	Solo string `json:"solo,omitempty"`
+
+	// Checked in order, the first rule that matches wins
+	Snakes []Rule `json:"snakes,omitempty"`
+}
+
+func Parse(data []byte) (*Config, error) {
+	c := &Config{}
+
+	err := json.Unmarshal(data, c)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	return c, nil
+}
+
+// Embedded returns the config in snakes.json alone, what to fall back on
+// when the layers on top of it are broken
+func Embedded() (*Config, error) {
+	c, err := Parse(embedded)
+	if err != nil {
+		return nil, err
+	}
+	return c, c.Validate()
+}
+
+// Load reads the embedded config, with the override written at build time
+// and then the one in the environment on top
+func Load() (*Config, error) {
+	c, err := Parse(embedded)
+	if err != nil {
+		return nil, err
+	}
+
+	over, err := Parse(override)
+	if err != nil {
+		return nil, fmt.Errorf("override.json: %w", err)
+	}
+	c.Merge(over)
+
+	if data := os.Getenv(EnvVar); data != "" {
+		over, err := Parse([]byte(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvVar, err)
+		}
+		c.Merge(over)
+	}
+
+	return c, c.Validate()
+}
+
+// Merge lays over on top of c: its default and solo strategies replace
+// those of c when set, and its rules are checked first
+func (c *Config) Merge(over *Config) {
+	if over.Default != "" {
+		c.Default = over.Default
+	}
+	if over.Solo != "" {
+		c.Solo = over.Solo
+	}
+	c.Snakes = append(append([]Rule(nil), over.Snakes...), c.Snakes...)
+}
+
+// Validate checks that every strategy named is registered
+func (c *Config) Validate() error {
+	for _, name := range []string{c.Default, c.Solo} {
+		if _, ok := strategy.Lookup(name); name != "" && !ok {
+			return fmt.Errorf("config: unknown strategy %q", name)
+		}
+	}
+
+	for i, rule := range c.Snakes {
+		if _, ok := strategy.Lookup(rule.Strategy); !ok {
+			return fmt.Errorf("config: snakes[%d]: unknown strategy %q", i, rule.Strategy)
+		}
+	}
+
+	return nil
+}
+
+// Match returns the first rule that applies to our snake in state
+func (c *Config) Match(key string, state *types.GameState) (Rule, bool) {
+	for _, rule := range c.Snakes {
+		if rule.Matches(key, state) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// ParamsOf returns the params of the first rule for the strategy with the
+// given name or alias, for strategies run by another one rather than for a
+// snake of their own
+func (c *Config) ParamsOf(name string) strategy.Params {
+	s, ok := strategy.Lookup(name)
+	if !ok {
+		return nil
+	}
+
+	for _, rule := range c.Snakes {
+		if other, ok := strategy.Lookup(rule.Strategy); ok && other.Name() == s.Name() {
+			return rule.Params
+		}
+	}
+	return nil
+}
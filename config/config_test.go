@@ -0,0 +1,147 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+func init() {
+	move := func(*types.GameState) types.BattlesnakeMoveResponse {
+		return types.BattlesnakeMoveResponse{Move: "up"}
+	}
+	strategy.Register(strategy.Func{StrategyName: "test-a", MoveFunc: move}, "test-a-alias")
+	strategy.Register(strategy.Func{StrategyName: "test-b", MoveFunc: move})
+}
+
+func TestParamsOf(t *testing.T) {
+	c := &Config{Snakes: []Rule{
+		{Name: "one", Strategy: "test-b"},
+		{Name: "two", Strategy: "test-a-alias", Params: strategy.Params{"depth": 2}},
+		{Name: "three", Strategy: "test-a", Params: strategy.Params{"depth": 3}},
+	}}
+
+	tests := []struct {
+		name string
+		want strategy.Params
+	}{
+		{name: "test-a", want: strategy.Params{"depth": 2}},
+		{name: "test-a-alias", want: strategy.Params{"depth": 2}},
+		{name: "test-b"},
+		{name: "unknown"},
+	}
+
+	for _, test := range tests {
+		if got := c.ParamsOf(test.name); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	c := &Config{
+		Default: "test-a",
+		Solo:    "test-a",
+		Snakes:  []Rule{{Name: "one", Strategy: "test-a"}},
+	}
+
+	c.Merge(&Config{Solo: "test-b", Snakes: []Rule{{Name: "two", Strategy: "test-b"}}})
+
+	want := &Config{
+		Default: "test-a",
+		Solo:    "test-b",
+		Snakes:  []Rule{{Name: "two", Strategy: "test-b"}, {Name: "one", Strategy: "test-a"}},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("got %+v, want %+v", c, want)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	c := &Config{Snakes: []Rule{
+		{Strategy: "test-a"}, // Matches nothing
+		{Name: "tau001", Source: "league", Strategy: "test-a", Params: strategy.Params{"rule": 1}},
+		{Name: "tau001", Strategy: "test-a", Params: strategy.Params{"rule": 2}},
+		{ID: "gs_1", Strategy: "test-b", Params: strategy.Params{"rule": 3}},
+	}}
+
+	state := func(name, id, source string) *types.GameState {
+		return &types.GameState{
+			Game: types.Game{Source: source},
+			You:  types.Battlesnake{Name: name, ID: id},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		state *types.GameState
+		rule  float64 // 0 for no match
+	}{
+		{name: "name and source", state: state("tau001", "gs_9", "league"), rule: 1},
+		{name: "first match wins", state: state("tau001", "gs_1", "custom"), rule: 2},
+		{name: "route", key: "tau001", state: state("other", "gs_9", "custom"), rule: 2},
+		{name: "id", state: state("other", "gs_1", "league"), rule: 3},
+		{name: "no match", key: "tau002", state: state("other", "gs_9", "league")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, ok := c.Match(test.key, test.state)
+			if test.rule == 0 {
+				if ok {
+					t.Errorf("matched %+v, want nothing", rule)
+				}
+				return
+			}
+			if !ok || rule.Params["rule"] != test.rule {
+				t.Errorf("matched %+v, want rule %v", rule, test.rule)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		valid  bool
+	}{
+		{name: "empty", valid: true},
+		{name: "valid", config: Config{Default: "test-a", Solo: "test-b", Snakes: []Rule{{Name: "one", Strategy: "test-b"}}}, valid: true},
+		{name: "aliases", config: Config{Default: "test-a-alias", Snakes: []Rule{{Name: "one", Strategy: "test-a-alias"}}}, valid: true},
+		{name: "unknown default", config: Config{Default: "nope"}},
+		{name: "unknown solo", config: Config{Solo: "nope"}},
+		{name: "unknown rule strategy", config: Config{Snakes: []Rule{{Name: "one", Strategy: "test-a"}, {Name: "two", Strategy: "nope"}}}},
+		{name: "rule without a strategy", config: Config{Snakes: []Rule{{Name: "one"}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.config.Validate()
+			if test.valid && err != nil {
+				t.Errorf("got %v, want no error", err)
+			}
+			if !test.valid && err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(`{"default": "test-a", "snakes": [{"name": "one", "strategy": "test-b", "params": {"depth": 4}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Config{Default: "test-a", Snakes: []Rule{{Name: "one", Strategy: "test-b", Params: strategy.Params{"depth": 4}}}}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("got %+v, want %+v", c, want)
+	}
+
+	if _, err := Parse([]byte(`{"snakes": {}}`)); err == nil {
+		t.Error("broken config: got no error")
+	}
+}
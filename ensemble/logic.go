@@ -0,0 +1,239 @@
+package ensemble
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/safety"
+	"github.com/samyfodil/tb_library_snake_001/shout"
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+const Name = "ensemble"
+
+// Weight of the vote of every member, by game mode. The weights under ""
+// apply to every mode, those of the mode being played are laid on top. A
+// member with no weight, or a weight of 0, does not play.
+var DefaultWeights = map[string]map[string]float64{
+	"": {
+		"v2": 1,
+		"v3": 1,
+		"v4": 2,
+		"v5": 1,
+	},
+	rules.Constrictor: {
+		"v5": 4,
+	},
+	rules.Wrapped: {
+		"v3": 0,
+	},
+}
+
+// MemberParams returns the params a member plays with, those of its own
+// entry in the config when the server sets it. The ensemble's own params,
+// weights and veto, are not passed on.
+var MemberParams = func(member string) strategy.Params {
+	return nil
+}
+
+// Share of the time left that members get, the rest is for counting votes
+const memberShare = 0.8
+
+// Helper functions
+
+// sortedKeys returns the keys of params in order, so that when two of them
+// set the same weight the same one always wins
+func sortedKeys(params strategy.Params) []string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// canonical returns the name of the strategy registered under key, a name
+// or an alias
+func canonical(key string) (string, bool) {
+	s, ok := strategy.Lookup(key)
+	if !ok {
+		return "", false
+	}
+	return s.Name(), true
+}
+
+// weights returns the weight of every member for the mode of state, by
+// strategy name. Params override DefaultWeights: "<member>" sets the weight
+// of a member in every mode, "<mode>.<member>" in one mode only. Members can
+// be named by alias, but only once per mode: a strategy named a second time
+// keeps the weight it was given under its own name, or else under the first
+// alias in order, so that it never votes twice.
+func weights(state *types.GameState, params strategy.Params) map[string]float64 {
+	mode := state.Game.Ruleset.Name
+
+	w := make(map[string]float64)
+	for member, weight := range DefaultWeights[""] {
+		w[member] = weight
+	}
+	for member, weight := range DefaultWeights[mode] {
+		w[member] = weight
+	}
+
+	// Weights given under prefix, one per member
+	set := func(prefix string) {
+		chosen := make(map[string]string)
+		for _, key := range sortedKeys(params) {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			member, ok := canonical(strings.TrimPrefix(key, prefix))
+			if !ok {
+				continue
+			}
+			if first, ok := chosen[member]; ok && (first == prefix+member || key != prefix+member) {
+				continue
+			}
+			chosen[member] = key
+		}
+		for member, key := range chosen {
+			w[member] = params[key]
+		}
+	}
+	set("")
+	if mode != "" {
+		set(mode + ".")
+	}
+
+	for member, weight := range w {
+		if weight <= 0 || member == Name {
+			delete(w, member)
+		}
+	}
+
+	return w
+}
+
+type vote struct {
+	member string
+	move   types.Direction
+}
+
+// ask gets the move of a single member, no move when it panics
+func ask(ctx context.Context, s strategy.Strategy, state *types.GameState) (move types.Direction) {
+	defer func() {
+		if recover() != nil {
+			move = types.NoDirection
+		}
+	}()
+
+	move, _ = types.ParseDirection(s.Move(ctx, state).Move)
+	return move
+}
+
+// poll runs the members one after the other, each on its own copy of state
+// and with an even share of the time left, and returns the votes of those
+// that answered with a valid move, in member order. Members run in turn
+// rather than at once so that nothing is left running once we have moved,
+// and so that the deadline holds on the single-threaded WASM target, where
+// nothing can cut a busy member short. Members left when time is up do not
+// vote, nor does a member that panics.
+func poll(ctx context.Context, state *types.GameState, members []string) []vote {
+	if deadline, ok := ctx.Deadline(); ok {
+		share := time.Duration(float64(time.Until(deadline)) * memberShare)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, share)
+		defer cancel()
+	}
+	deadline, hasDeadline := ctx.Deadline()
+
+	votes := make([]vote, 0, len(members))
+	for i, member := range members {
+		if ctx.Err() != nil {
+			break
+		}
+
+		s, ok := strategy.Lookup(member)
+		if !ok {
+			continue
+		}
+
+		memberCtx, cancel := ctx, context.CancelFunc(func() {})
+		if hasDeadline {
+			memberCtx, cancel = context.WithTimeout(ctx, time.Until(deadline)/time.Duration(len(members)-i))
+		}
+		memberCtx = strategy.WithParams(memberCtx, MemberParams(member))
+		move := ask(memberCtx, s, rules.Clone(state))
+		cancel()
+
+		if move.Valid() {
+			votes = append(votes, vote{member: member, move: move})
+		}
+	}
+
+	return votes
+}
+
+// Main logic
+
+// Move runs a set of strategies on the same state and goes with the move
+// with the most weighted votes. Ties go to the move with the most room. With
+// the veto param set, moves the safety layer finds into a wall, a body or a
+// space too small to hold us are out, whatever the votes.
+func Move(ctx context.Context, state *types.GameState) types.BattlesnakeMoveResponse {
+	params := strategy.ParamsFrom(ctx)
+	w := weights(state, params)
+	veto := params.Int("veto", 0) != 0
+
+	members := make([]string, 0, len(w))
+	for member := range w {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	// Before any member gets to change the state
+	room := safety.Room(state)
+	vetoed := func(move types.Direction) bool {
+		r, ok := room[move]
+		return !ok || r < len(state.You.Body)
+	}
+
+	votes := poll(ctx, state, members)
+
+	tally := make(map[types.Direction]float64)
+	voters := make(map[types.Direction][]string)
+	total := 0.0
+	for _, v := range votes {
+		total += w[v.member]
+		if veto && vetoed(v.move) {
+			continue
+		}
+		tally[v.move] += w[v.member]
+		voters[v.move] = append(voters[v.move], v.member)
+	}
+
+	best := types.NoDirection
+	for _, move := range types.AllDirections() {
+		score, ok := tally[move]
+		if !ok {
+			continue
+		}
+		if !best.Valid() || score > tally[best] || (score == tally[best] && room[move] > room[best]) {
+			best = move
+		}
+	}
+
+	if !best.Valid() {
+		response := safety.Move(state)
+		response.Shout = shout.Fallback("no votes left")
+		return response
+	}
+
+	return types.BattlesnakeMoveResponse{
+		Move:  best.String(),
+		Shout: shout.Format("%s voted %s, %.1f of %.1f", strings.Join(voters[best], "+"), best, tally[best], total),
+	}
+}
@@ -0,0 +1,215 @@
+package ensemble
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Params the test-params member was last run with
+var seenParams strategy.Params
+
+func init() {
+	always := func(move types.Direction) func(*types.GameState) types.BattlesnakeMoveResponse {
+		return func(*types.GameState) types.BattlesnakeMoveResponse {
+			return types.BattlesnakeMoveResponse{Move: move.String()}
+		}
+	}
+
+	strategy.Register(strategy.Func{StrategyName: "test-up", MoveFunc: always(types.Up)}, "test-up-alias")
+	strategy.Register(strategy.Func{StrategyName: "test-down", MoveFunc: always(types.Down)})
+	strategy.Register(strategy.Func{StrategyName: "test-left", MoveFunc: always(types.Left)})
+	strategy.Register(strategy.Func{StrategyName: "test-panic", MoveFunc: func(*types.GameState) types.BattlesnakeMoveResponse {
+		panic("test")
+	}})
+	strategy.Register(strategy.Func{StrategyName: "test-params", SearchFunc: func(ctx context.Context, state *types.GameState) types.BattlesnakeMoveResponse {
+		seenParams = strategy.ParamsFrom(ctx)
+		return types.BattlesnakeMoveResponse{Move: types.Down.String()}
+	}})
+}
+
+// Helper functions
+
+// testState has us against the left wall, heading left
+func testState(ruleset string) *types.GameState {
+	us := types.Battlesnake{
+		ID:     "us",
+		Health: 90,
+		Body:   []types.Coord{{X: 0, Y: 3}, {X: 1, Y: 3}, {X: 2, Y: 3}},
+		Head:   types.Coord{X: 0, Y: 3},
+		Length: 3,
+	}
+	return &types.GameState{
+		Game:  types.Game{ID: "ensemble", Ruleset: types.Ruleset{Name: ruleset}},
+		Board: types.Board{Width: 7, Height: 7, Snakes: []types.Battlesnake{us}},
+		You:   us,
+	}
+}
+
+// withoutDefaults leaves the members weighed by params the only ones until
+// the test is over
+func withoutDefaults(t *testing.T) {
+	saved := DefaultWeights
+	DefaultWeights = map[string]map[string]float64{}
+	t.Cleanup(func() { DefaultWeights = saved })
+}
+
+// Tests
+
+func TestWeights(t *testing.T) {
+	defaults := func(mode string) map[string]float64 {
+		w := make(map[string]float64)
+		for member, weight := range DefaultWeights[""] {
+			w[member] = weight
+		}
+		for member, weight := range DefaultWeights[mode] {
+			w[member] = weight
+		}
+		for member, weight := range w {
+			if weight <= 0 {
+				delete(w, member)
+			}
+		}
+		return w
+	}
+
+	tests := []struct {
+		name   string
+		mode   string
+		params strategy.Params
+		want   map[string]float64
+	}{
+		{
+			name: "defaults",
+			mode: rules.Standard,
+			want: defaults(rules.Standard),
+		},
+		{
+			name: "defaults of the mode",
+			mode: rules.Constrictor,
+			want: defaults(rules.Constrictor),
+		},
+		{
+			name:   "every mode",
+			mode:   rules.Standard,
+			params: strategy.Params{"test-up": 2, "veto": 1},
+			want:   map[string]float64{"test-up": 2},
+		},
+		{
+			name:   "one mode only",
+			mode:   rules.Wrapped,
+			params: strategy.Params{"test-up": 2, "wrapped.test-up": 3, "constrictor.test-down": 1},
+			want:   map[string]float64{"test-up": 3},
+		},
+		{
+			name:   "alias",
+			mode:   rules.Standard,
+			params: strategy.Params{"test-up-alias": 2},
+			want:   map[string]float64{"test-up": 2},
+		},
+		{
+			name:   "name and alias vote once, by name",
+			mode:   rules.Standard,
+			params: strategy.Params{"test-up": 1, "test-up-alias": 5},
+			want:   map[string]float64{"test-up": 1},
+		},
+		{
+			name:   "zero drops a member",
+			mode:   rules.Standard,
+			params: strategy.Params{"test-up": 1, "test-down": 0},
+			want:   map[string]float64{"test-up": 1},
+		},
+		{
+			name:   "never itself",
+			mode:   rules.Standard,
+			params: strategy.Params{"test-up": 1, Name: 1, "tau011": 1},
+			want:   map[string]float64{"test-up": 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.params != nil {
+				withoutDefaults(t)
+			}
+			got := weights(testState(test.mode), test.params)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMove(t *testing.T) {
+	tests := []struct {
+		name   string
+		params strategy.Params
+		want   types.Direction
+	}{
+		{
+			name:   "most weight wins",
+			params: strategy.Params{"test-up": 1, "test-left": 2},
+			want:   types.Left,
+		},
+		{
+			name:   "votes add up",
+			params: strategy.Params{"test-up": 1, "test-down": 1, "test-params": 1.5, "test-left": 2},
+			want:   types.Down,
+		},
+		{
+			name:   "veto",
+			params: strategy.Params{"test-up": 1, "test-left": 2, "veto": 1},
+			want:   types.Up,
+		},
+		{
+			name:   "an alias does not vote twice",
+			params: strategy.Params{"test-up": 1, "test-up-alias": 5, "test-left": 2},
+			want:   types.Left,
+		},
+		{
+			name:   "a member that panics does not vote",
+			params: strategy.Params{"test-panic": 5, "test-up": 1},
+			want:   types.Up,
+		},
+		{
+			name:   "no votes left falls back to a safe move",
+			params: strategy.Params{"test-left": 1, "veto": 1},
+			want:   types.Up,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withoutDefaults(t)
+			ctx := strategy.WithParams(context.Background(), test.params)
+			response := Move(ctx, testState(rules.Standard))
+			if response.Move != test.want.String() {
+				t.Errorf("moved %q, want %q (%s)", response.Move, test.want, response.Shout)
+			}
+		})
+	}
+}
+
+func TestMemberParams(t *testing.T) {
+	defer func(saved func(string) strategy.Params) { MemberParams = saved }(MemberParams)
+
+	own := strategy.Params{"lookStepsAhead": 3}
+	MemberParams = func(member string) strategy.Params {
+		if member == "test-params" {
+			return own
+		}
+		return nil
+	}
+
+	withoutDefaults(t)
+	ctx := strategy.WithParams(context.Background(), strategy.Params{"test-params": 1, "lookStepsAhead": 9, "veto": 1})
+	Move(ctx, testState(rules.Standard))
+
+	if !reflect.DeepEqual(seenParams, own) {
+		t.Errorf("member ran with %v, want its own %v", seenParams, own)
+	}
+}
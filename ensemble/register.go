@@ -0,0 +1,21 @@
+package ensemble
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+const author = "samyfodil"
+
+func init() {
+	strategy.Register(strategy.Func{
+		StrategyName: Name,
+		SearchFunc:   Move,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#34495e",
+			Head:   "smart-caterpillar",
+			Tail:   "coffee",
+		},
+	}, "tau011")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// HTTP statuses our routes answer with
+const (
+	statusOK                  = 200
+	statusBadRequest          = 400
+	statusNotFound            = 404
+	statusTooLarge            = 413
+	statusInternalServerError = 500
+)
+
+// requestError is a failure to answer a request, along with the HTTP status
+// it calls for
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// errUnknownSnake is returned for a snake route no strategy is registered for
+var errUnknownSnake = &requestError{status: statusNotFound, err: errors.New("unknown snake")}
+
+// badRequest marks err as the fault of the request, a body that could not be
+// read or decoded, or that was too large to
+func badRequest(err error) error {
+	var tooLarge *types.TooLargeError
+	if errors.As(err, &tooLarge) {
+		return &requestError{status: statusTooLarge, err: err}
+	}
+	return &requestError{status: statusBadRequest, err: err}
+}
+
+// statusOf returns the HTTP status to answer err with, errors that are not
+// the request's fault are ours
+func statusOf(err error) int {
+	var re *requestError
+	if errors.As(err, &re) {
+		return re.status
+	}
+	return statusInternalServerError
+}
+
+// errorBody returns err as a JSON error object
+func errorBody(err error) []byte {
+	response := types.ErrorResponse{Error: err.Error()}
+	data, _ := response.MarshalJSON()
+	return data
+}
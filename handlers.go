@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/samyfodil/tb_library_snake_001/config"
+	"github.com/samyfodil/tb_library_snake_001/ensemble"
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/safety"
+	"github.com/samyfodil/tb_library_snake_001/session"
+	"github.com/samyfodil/tb_library_snake_001/shout"
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+	v6 "github.com/samyfodil/tb_library_snake_001/v6"
+
+	// Strategies register themselves with the strategy package
+	_ "github.com/samyfodil/tb_library_snake_001/v1"
+	_ "github.com/samyfodil/tb_library_snake_001/v2"
+	_ "github.com/samyfodil/tb_library_snake_001/v3"
+	_ "github.com/samyfodil/tb_library_snake_001/v4"
+	_ "github.com/samyfodil/tb_library_snake_001/v5"
+)
+
+// Timeout of games that do not say, the engine's default
+const defaultTimeout = 500 * time.Millisecond
+
+// Largest request body read, past it the request is refused
+var maxBodySize int64 = types.MaxGameStateSize
+
+// Sessions of the games being played. Kept in memory unless the build
+// replaces the store.
+var sessions = session.NewManager(session.NewMemoryStore(), session.DefaultTTL)
+
+// Which strategy plays which snake, see config/snakes.json
+var snakes *config.Config
+
+// loadConfig reads the config with its overrides. A broken override is
+// logged and left out rather than taking down every snake on the instance
+// with it.
+func loadConfig() *config.Config {
+	c, err := config.Load()
+	if err == nil {
+		return c
+	}
+	log.Printf("loading the snake config, going with snakes.json alone: %s", err)
+
+	c, err = config.Embedded()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func init() {
+	snakes = loadConfig()
+
+	// Strategies the ensemble runs play as they do for their own snakes
+	ensemble.MemberParams = snakes.ParamsOf
+
+	if snakes.Default != "" {
+		err := strategy.SetDefault(snakes.Default)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Transport independent handlers, shared by the Taubyte exports in
+// server.go and the native HTTP server
+
+// snakeKey returns the snake a request path is for. Every snake served by
+// this deployment has its own base URL, /<name>/ for the info route and
+// /<name>/start, /<name>/move and /<name>/end for the game. The bare routes
+// are for no snake in particular and give an empty key.
+func snakeKey(path string) string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return ""
+	}
+
+	key, _, _ := strings.Cut(path, "/")
+	switch key {
+	case "start", "move", "end":
+		return ""
+	}
+
+	return key
+}
+
+func handleIndex(key string) ([]byte, error) {
+	response := info()
+
+	if key != "" {
+		s, ok := strategy.Lookup(key)
+		if !ok {
+			return nil, errUnknownSnake
+		}
+		if informer, ok := s.(strategy.Informer); ok {
+			response = informer.Info()
+		}
+	}
+
+	return response.MarshalJSON()
+}
+
+// decodeState reads the state a request comes with out of body, into a
+// state from the pool that the caller releases. The state is returned even
+// along with an error, decoded as far as it goes.
+func decodeState(body io.Reader) (*types.GameState, error) {
+	state := types.AcquireGameState()
+
+	err := types.DecodeGameState(body, maxBodySize, state)
+	if err == nil {
+		err = state.Normalize()
+	}
+	if err != nil {
+		return state, badRequest(err)
+	}
+
+	return state, nil
+}
+
+func handleStart(key string, body io.Reader) error {
+	state, err := decodeState(body)
+	defer types.ReleaseGameState(state)
+	if err != nil {
+		return err
+	}
+
+	s, _ := pickStrategy(key, state)
+	seen := rules.Clone(state)
+
+	_, err = sessions.Start(seen, strategyName(s))
+	if err != nil {
+		return err
+	}
+
+	if starter, ok := s.(strategy.Starter); ok {
+		guard(s, seen, func() {
+			starter.Start(state)
+		})
+	}
+
+	return nil
+}
+
+// handleMove always comes up with a move to answer with. When the body is
+// cut short, too large, broken or not a valid state, the error comes back
+// along with the safest move that can be made out of whatever part of the
+// state was read.
+func handleMove(key string, body io.Reader) ([]byte, error) {
+	received := time.Now()
+
+	state, err := decodeState(body)
+	defer types.ReleaseGameState(state)
+	if err != nil {
+		response := safety.Move(state)
+		response.Shout = shout.Fallback("could not read the game")
+		data, _ := response.MarshalJSON()
+		return data, err
+	}
+
+	s, params := pickStrategy(key, state)
+
+	// Strategies are free to change the state they are given, the session
+	// keeps it as it came in
+	seen := rules.Clone(state)
+
+	sess, err := sessions.Move(seen, strategyName(s))
+	if err != nil {
+		log.Printf("loading session of %s: %s", state.Game.ID, err)
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), received.Add(moveBudget(state, sess)))
+	defer cancel()
+	ctx = strategy.WithParams(ctx, params)
+	if sess != nil {
+		ctx = session.NewContext(ctx, sess)
+	}
+
+	response := chooseMove(ctx, s, state, seen)
+
+	if sess != nil {
+		sess.RecordCompute(state.Turn, time.Since(received))
+		err = sessions.Save(sess, seen)
+		if err != nil {
+			log.Printf("saving session of %s: %s", state.Game.ID, err)
+		}
+	}
+
+	return response.MarshalJSON()
+}
+
+// eliminated reports whether err only says that we are not on the board, as
+// in the /end of a game we lost
+func eliminated(err error) bool {
+	var invalid *types.ValidationError
+	if !errors.As(err, &invalid) {
+		return false
+	}
+	for _, v := range invalid.Violations {
+		if v.Invariant != types.MissingYou {
+			return false
+		}
+	}
+	return true
+}
+
+// handleEnd wraps up the game. The engine sends /end after we are
+// eliminated too, without us on the board, which is fine here.
+func handleEnd(key string, body io.Reader) error {
+	state, err := decodeState(body)
+	defer types.ReleaseGameState(state)
+	if err != nil && !eliminated(err) {
+		return err
+	}
+
+	s, _ := pickStrategy(key, state)
+	if ender, ok := s.(strategy.Ender); ok {
+		guard(s, rules.Clone(state), func() {
+			ender.End(state)
+		})
+	}
+
+	_, err = sessions.End(state)
+	return err
+}
+
+// chooseMove runs the strategy picked for our snake on state. When there is
+// no strategy, or it panics or comes up with no valid move, the safety layer
+// moves instead, on seen, the state as it came in.
+func chooseMove(ctx context.Context, s strategy.Strategy, state, seen *types.GameState) types.BattlesnakeMoveResponse {
+	if s == nil {
+		return safety.Move(seen)
+	}
+
+	var response types.BattlesnakeMoveResponse
+	ok := guard(s, seen, func() {
+		response = s.Move(ctx, state)
+	})
+	if !ok {
+		fallback := safety.Move(seen)
+		fallback.Shout = shout.Fallback(s.Name() + " crashed")
+		return fallback
+	}
+
+	if !isValidMove(response.Move) {
+		log.Printf("strategy %s moved %q on turn %d of %s", s.Name(), response.Move, seen.Turn, seen.Game.ID)
+		fallback := safety.Move(seen)
+		fallback.Shout = shout.Fallback(s.Name() + " had no move")
+		return fallback
+	}
+
+	// Strategies are free to say more than the engine keeps
+	response.Shout = shout.Limit(response.Shout)
+
+	return response
+}
+
+// guard runs a call into a strategy, recovering from any panic. The panic is
+// logged along with the state the strategy was given, and false returned.
+func guard(s strategy.Strategy, state *types.GameState, call func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			data, _ := state.MarshalJSON()
+			log.Printf("strategy %s panicked on turn %d of %s: %v\n%s\nstate: %s", s.Name(), state.Turn, state.Game.ID, r, debug.Stack(), data)
+			ok = false
+		}
+	}()
+
+	call()
+	return true
+}
+
+func isValidMove(move string) bool {
+	_, err := types.ParseDirection(move)
+	return err == nil
+}
+
+// moveBudget is how long we can take to answer a move, the timeout of the
+// game less the time the network is expected to take
+func moveBudget(state *types.GameState, sess *session.Session) time.Duration {
+	timeout := time.Duration(state.Game.Timeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	margin := session.DefaultNetworkMargin
+	if sess != nil {
+		margin = sess.NetworkMargin()
+	}
+
+	// However slow the network, leave strategies something to work with
+	budget := timeout - margin
+	if budget < timeout/10 {
+		budget = timeout / 10
+	}
+
+	return budget
+}
+
+// pickStrategy finds the strategy for our snake, and the params it plays
+// with. Solo games always go to the solo strategy, whatever the snake. Then
+// the config decides, and failing that the strategy registered under the
+// snake route the request came in on, the snake's name or its ID.
+func pickStrategy(key string, state *types.GameState) (strategy.Strategy, strategy.Params) {
+	if v6.IsSolo(state) {
+		if s, ok := strategy.Lookup(snakes.Solo); ok {
+			return s, nil
+		}
+	}
+
+	if rule, ok := snakes.Match(key, state); ok {
+		if s, ok := strategy.Lookup(rule.Strategy); ok {
+			return s, rule.Params
+		}
+	}
+
+	if s, ok := strategy.Lookup(key); ok {
+		return s, nil
+	}
+
+	return strategy.For(state), nil
+}
+
+func strategyName(s strategy.Strategy) string {
+	if s == nil {
+		return ""
+	}
+	return s.Name()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/samyfodil/tb_library_snake_001/config"
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/session"
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+const startBody = `{
+	"game": {"id": "end-test", "ruleset": {"name": "standard"}, "timeout": 500},
+	"turn": 0,
+	"board": {"width": 11, "height": 11, "food": [], "hazards": [], "snakes": [
+		{"id": "us", "health": 100, "body": [{"x": 1, "y": 1}, {"x": 1, "y": 1}, {"x": 1, "y": 1}]},
+		{"id": "them", "health": 100, "body": [{"x": 9, "y": 9}, {"x": 9, "y": 9}, {"x": 9, "y": 9}]}
+	]},
+	"you": {"id": "us", "health": 100, "body": [{"x": 1, "y": 1}, {"x": 1, "y": 1}, {"x": 1, "y": 1}]}
+}`
+
+// What the engine sends at the end of a game we lost: we are gone from the
+// board, you is the snake as it was eliminated
+const lostBody = `{
+	"game": {"id": "end-test", "ruleset": {"name": "standard"}, "timeout": 500},
+	"turn": 12,
+	"board": {"width": 11, "height": 11, "food": [], "hazards": [], "snakes": [
+		{"id": "them", "health": 88, "body": [{"x": 9, "y": 3}, {"x": 9, "y": 4}, {"x": 9, "y": 5}]}
+	]},
+	"you": {"id": "us", "health": 0, "body": [{"x": 1, "y": 10}, {"x": 1, "y": 9}, {"x": 1, "y": 8}]}
+}`
+
+func TestHandleEndAfterElimination(t *testing.T) {
+	if err := handleStart("", strings.NewReader(startBody)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handleEnd("", strings.NewReader(lostBody)); err != nil {
+		t.Fatalf("end of a lost game: %s", err)
+	}
+
+	_, err := sessions.Load(&types.GameState{Game: types.Game{ID: "end-test"}, You: types.Battlesnake{ID: "us"}})
+	if !errors.Is(err, session.ErrNotFound) {
+		t.Errorf("session still there after /end: %v", err)
+	}
+}
+
+func TestHandleEndInvalid(t *testing.T) {
+	broken := strings.Replace(lostBody, `"width": 11`, `"width": 0`, 1)
+
+	err := handleEnd("", strings.NewReader(broken))
+	if statusOf(err) != statusBadRequest {
+		t.Errorf("got %v, want a bad request", err)
+	}
+}
+
+func TestEmbeddedConfig(t *testing.T) {
+	if _, err := config.Embedded(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigFallback(t *testing.T) {
+	embedded, err := config.Embedded()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, override := range []string{
+		`{"default": "no-such-strategy"}`,
+		`{"snakes": [{"name": "tau001", "strategy": "no-such-strategy"}]}`,
+		`not json`,
+	} {
+		t.Setenv(config.EnvVar, override)
+		if got := loadConfig(); !reflect.DeepEqual(got, embedded) {
+			t.Errorf("%s: got %+v, want the embedded config", override, got)
+		}
+	}
+
+	// A valid override is laid on top
+	t.Setenv(config.EnvVar, `{"default": "v3"}`)
+	if got := loadConfig(); got.Default != "v3" {
+		t.Errorf("valid override: default %q, want v3", got.Default)
+	}
+}
+
+func TestChooseMoveFallback(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	state, err := decodeState(strings.NewReader(startBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer types.ReleaseGameState(state)
+
+	tests := []struct {
+		name   string
+		move   func(*types.GameState) types.BattlesnakeMoveResponse
+		logged []string
+	}{
+		{
+			name: "panic",
+			move: func(state *types.GameState) types.BattlesnakeMoveResponse {
+				var moves []types.Direction
+				return types.BattlesnakeMoveResponse{Move: moves[0].String()}
+			},
+			// Along with the state, to reproduce the panic
+			logged: []string{"strategy test-panic panicked", `"id":"end-test"`},
+		},
+		{
+			name: "no move",
+			move: func(state *types.GameState) types.BattlesnakeMoveResponse {
+				return types.BattlesnakeMoveResponse{Move: "sideways"}
+			},
+			logged: []string{`strategy test-no move moved "sideways"`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logged.Reset()
+			s := strategy.Func{StrategyName: "test-" + test.name, MoveFunc: test.move}
+
+			response := chooseMove(context.Background(), s, state, rules.Clone(state))
+
+			if !isValidMove(response.Move) {
+				t.Errorf("moved %q, want a legal move", response.Move)
+			}
+			for _, want := range test.logged {
+				if !strings.Contains(logged.String(), want) {
+					t.Errorf("logged %q, want it to say %q", logged.String(), want)
+				}
+			}
+		})
+	}
+}
@@ -2,5 +2,108 @@
 
 package main
 
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+)
+
 func main() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8000"
+	}
+
+	addr := flag.String("addr", ":"+port, "address to listen on")
+	fallback := flag.String("strategy", "", "strategy for snakes the config has no rule for, overrides its default")
+	flag.Parse()
+
+	if *fallback != "" {
+		err := strategy.SetDefault(*fallback)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	http.HandleFunc("/", serve)
+
+	log.Printf("Running Battlesnake at http://%s\n", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// Native HTTP Handlers, mirroring the Taubyte exports in server.go
+
+// serve routes on the last element of the path, so the routes of every snake
+// share the handlers of the bare ones
+func serve(w http.ResponseWriter, r *http.Request) {
+	key := snakeKey(r.URL.Path)
+
+	switch path.Base(r.URL.Path) {
+	case "start":
+		serveStart(w, r, key)
+	case "move":
+		serveMove(w, r, key)
+	case "end":
+		serveEnd(w, r, key)
+	default:
+		serveIndex(w, r, key)
+	}
+}
+
+func serveIndex(w http.ResponseWriter, r *http.Request, key string) {
+	w.Header().Set("Server", ServerID)
+	w.Header().Set("Content-Type", "application/json")
+
+	data, err := handleIndex(key)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Write(data)
+}
+
+func serveStart(w http.ResponseWriter, r *http.Request, key string) {
+	w.Header().Set("Server", ServerID)
+
+	err := handleStart(key, r.Body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+}
+
+// serveMove answers with a move whatever happens, a body that could only be
+// read in part is decoded as far as it goes
+func serveMove(w http.ResponseWriter, r *http.Request, key string) {
+	w.Header().Set("Server", ServerID)
+
+	data, err := handleMove(key, r.Body)
+	if err != nil {
+		log.Printf("move: %s", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func serveEnd(w http.ResponseWriter, r *http.Request, key string) {
+	w.Header().Set("Server", ServerID)
+
+	err := handleEnd(key, r.Body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusOf(err))
+	w.Write(errorBody(err))
 }
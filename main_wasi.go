@@ -2,6 +2,16 @@
 
 package main
 
+import (
+	"github.com/samyfodil/tb_library_snake_001/session"
+)
+
+func init() {
+	// An instance is not guaranteed to serve every turn of a game, sessions
+	// are kept in the project's database instead
+	sessions.Store = session.NewDatabaseStore("sessions")
+}
+
 //export _ready
 func ready()
 
@@ -3,17 +3,13 @@ package main
 import (
 	"math"
 	"math/rand"
-	"time"
 
+	"github.com/samyfodil/tb_library_snake_001/rules"
 	"github.com/samyfodil/tb_library_snake_001/types"
 )
 
 type MoveHelper struct{}
 
-func (mh MoveHelper) isOutOfBounds(point types.Coord, width int, height int) bool {
-	return point.X < 0 || point.Y < 0 || point.X >= width || point.Y >= height
-}
-
 func (mh MoveHelper) isBodyCollision(point types.Coord, body []types.Coord) bool {
 	for _, bodyPart := range body {
 		if point.X == bodyPart.X && point.Y == bodyPart.Y {
@@ -23,39 +19,27 @@ func (mh MoveHelper) isBodyCollision(point types.Coord, body []types.Coord) bool
 	return false
 }
 
-func (mh MoveHelper) getAllowedMoves(state *types.GameState) []string {
-	allowedMoves := []string{"up", "down", "left", "right"}
+func (mh MoveHelper) getAllowedMoves(state *types.GameState) []types.Direction {
+	allowedMoves := make([]types.Direction, 0, 4)
 
 	head := state.You.Body[0]
-	width := state.Board.Width
-	height := state.Board.Height
+	geo := rules.GeometryOf(state)
 
-	for _, move := range allowedMoves {
-		newHead := mh.getNewHead(head, move)
-		if mh.isOutOfBounds(newHead, width, height) || mh.isBodyCollision(newHead, state.You.Body) {
-			allowedMoves = mh.remove(allowedMoves, move)
+	for _, move := range types.AllDirections() {
+		newHead := mh.getNewHead(geo, head, move)
+		if geo.InBounds(newHead) && !mh.isBodyCollision(newHead, state.You.Body) {
+			allowedMoves = append(allowedMoves, move)
 		}
 	}
 
 	return allowedMoves
 }
 
-func (mh MoveHelper) getNewHead(head types.Coord, move string) types.Coord {
-	newHead := head
-	switch move {
-	case "up":
-		newHead.Y++
-	case "down":
-		newHead.Y--
-	case "left":
-		newHead.X--
-	case "right":
-		newHead.X++
-	}
-	return newHead
+func (mh MoveHelper) getNewHead(geo rules.Geometry, head types.Coord, move types.Direction) types.Coord {
+	return geo.Move(head, move)
 }
 
-func (mh MoveHelper) remove(slice []string, s string) []string {
+func (mh MoveHelper) remove(slice []types.Direction, s types.Direction) []types.Direction {
 	index := -1
 	for i, v := range slice {
 		if v == s {
@@ -77,25 +61,21 @@ func (mh MoveHelper) euclideanDistance(a types.Coord, b types.Coord) float64 {
 	return math.Sqrt(dx*dx + dy*dy)
 }
 
-func (mh MoveHelper) randFloat() float64 {
-	rand.Seed(time.Now().UnixNano())
-	return rand.Float64()
+func (mh MoveHelper) randFloat(r *rand.Rand) float64 {
+	return r.Float64()
 }
 
-func (mh MoveHelper) isMoveSafe(state *types.GameState, move string) bool {
-	newHead := mh.getNewHead(state.You.Body[0], move)
-	return mh.isInBounds(newHead, state.Board) && !mh.isCollidingWithSelf(newHead, state.You.Body)
+func (mh MoveHelper) isMoveSafe(state *types.GameState, move types.Direction) bool {
+	geo := rules.GeometryOf(state)
+	newHead := mh.getNewHead(geo, state.You.Body[0], move)
+	return geo.InBounds(newHead) && !mh.isCollidingWithSelf(newHead, state.You.Body)
 }
 
-func (mh MoveHelper) getCollisionScore(state *types.GameState, move string) int {
-	newHead := mh.getNewHead(state.You.Body[0], move)
+func (mh MoveHelper) getCollisionScore(state *types.GameState, move types.Direction) int {
+	newHead := mh.getNewHead(rules.GeometryOf(state), state.You.Body[0], move)
 	return mh.distanceToClosestCollision(newHead, state.Board)
 }
 
-func (mh MoveHelper) isInBounds(coord types.Coord, board types.Board) bool {
-	return coord.X >= 0 && coord.X < board.Width && coord.Y >= 0 && coord.Y < board.Height
-}
-
 func (mh MoveHelper) isCollidingWithSelf(newHead types.Coord, body []types.Coord) bool {
 	for _, bodyPart := range body {
 		if newHead.X == bodyPart.X && newHead.Y == bodyPart.Y {
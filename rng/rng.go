@@ -0,0 +1,31 @@
+package rng
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Seeder picks the seed New uses for a state. Replace it to pin down the
+// random sequence, e.g. in tests.
+var Seeder = Seed
+
+// New returns the random source a strategy should use for this move.
+// Strategies must not use the global math/rand functions, so that replaying
+// the same state always gives the same move.
+func New(state *types.GameState) *rand.Rand {
+	return rand.New(rand.NewSource(Seeder(state)))
+}
+
+// Seed derives a seed from the game ID, the turn and our snake's ID
+func Seed(state *types.GameState) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(state.Game.ID))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(state.Turn)))
+	h.Write([]byte{0})
+	h.Write([]byte(state.You.ID))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,244 @@
+package rules
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Given by Compare for a snake that is gone for a reason the boards do not
+// show, like when the states are turns apart
+const CauseUnknown EliminationCause = "unknown"
+
+// SnakeChange is what happened to one snake between two turns
+type SnakeChange struct {
+	ID string
+
+	// Move the snake made, NoDirection when it cannot be told: the snake was
+	// eliminated, or its head did not move to a neighboring cell
+	Move types.Direction
+
+	Ate    bool
+	Health int // Health after minus health before, to 0 when eliminated
+	Grew   int // Segments gained
+
+	// Likely reason the snake left the board, "" while it is still on it.
+	// By is the other snake involved, if any.
+	Eliminated EliminationCause
+	By         string
+}
+
+// Diff is what changed between two states of the same game
+type Diff struct {
+	From int // Turn of the earlier state
+	To   int // Turn of the later state
+
+	// Every snake on the board in the earlier state, in its order
+	Snakes []SnakeChange
+
+	FoodEaten   []types.Coord
+	FoodSpawned []types.Coord
+
+	// Hazards that appeared or went away, once per stack
+	HazardsAdded   []types.Coord
+	HazardsRemoved []types.Coord
+}
+
+// Snake returns the change of the snake with the given ID
+func (d *Diff) Snake(id string) (SnakeChange, bool) {
+	for _, change := range d.Snakes {
+		if change.ID == id {
+			return change, true
+		}
+	}
+	return SnakeChange{}, false
+}
+
+// Eliminated returns the snakes that left the board, in the order of the
+// earlier state
+func (d *Diff) Eliminated() []SnakeChange {
+	var eliminated []SnakeChange
+	for _, change := range d.Snakes {
+		if change.Eliminated != "" {
+			eliminated = append(eliminated, change)
+		}
+	}
+	return eliminated
+}
+
+// Helper functions
+
+// without returns the coords of a missing from b, stacked coords counted
+// once per copy
+func without(a, b []types.Coord) []types.Coord {
+	count := make(map[types.Coord]int, len(b))
+	for _, coord := range b {
+		count[coord]++
+	}
+
+	var missing []types.Coord
+	for _, coord := range a {
+		if count[coord] > 0 {
+			count[coord]--
+			continue
+		}
+		missing = append(missing, coord)
+	}
+	return missing
+}
+
+func snakeByID(state *types.GameState, id string) (types.Battlesnake, bool) {
+	for _, snake := range state.Board.Snakes {
+		if snake.ID == id {
+			return snake, true
+		}
+	}
+	return types.Battlesnake{}, false
+}
+
+// trailing returns the segments behind the head of snake after a move: the
+// body it had but its tail, all of it when it grew
+func trailing(snake types.Battlesnake, grew bool) []types.Coord {
+	if grew || len(snake.Body) == 0 {
+		return snake.Body
+	}
+	return snake.Body[:len(snake.Body)-1]
+}
+
+// inferElimination finds why snake, on the board in prev, is gone from
+// next. Every move the snake could have made is played against the engine's
+// checks, in the engine's order, its current direction first since that is
+// where a snake that timed out goes, and back into its neck last since no
+// strategy goes there. The first move that explains the elimination gives
+// the cause.
+func inferElimination(prev, next *types.GameState, snake types.Battlesnake) (EliminationCause, string) {
+	if len(snake.Body) == 0 {
+		return "", ""
+	}
+
+	geo := GeometryOf(prev)
+	constrictor := prev.Game.Ruleset.Name == Constrictor
+	damage := HazardDamage(prev)
+
+	first := currentDirection(geo, snake.Body)
+	moves := [4]types.Direction{first}
+	n := 1
+	for _, move := range types.AllDirections() {
+		if move != first && move != first.Opposite() {
+			moves[n] = move
+			n++
+		}
+	}
+	moves[n] = first.Opposite()
+
+	// A snake left with no body in next is as gone as one that is not in it
+	after := func(other types.Battlesnake) ([]types.Coord, bool) {
+		s, ok := snakeByID(next, other.ID)
+		return s.Body, ok && len(s.Body) > 0
+	}
+
+	for _, move := range moves {
+		head := geo.Move(snake.Body[0], move)
+		ate := isCoordInList(head, prev.Board.Food)
+
+		health := snake.Health - 1
+		if !ate {
+			for _, hazard := range prev.Board.Hazards {
+				if hazard == head {
+					health -= damage
+				}
+			}
+		}
+		if ate || constrictor {
+			health = MaxHealth
+		}
+		length := len(snake.Body)
+		if ate || constrictor {
+			length++
+		}
+
+		switch {
+		case health <= 0:
+			return CauseOutOfHealth, ""
+		case !geo.InBounds(head):
+			return CauseOutOfBounds, ""
+		case isCoordInList(head, trailing(snake, ate || constrictor)):
+			return CauseSelfCollision, snake.ID
+		}
+
+		for _, other := range prev.Board.Snakes {
+			if other.ID == snake.ID || len(other.Body) == 0 {
+				continue
+			}
+			body, alive := after(other)
+			if alive && isCoordInList(head, body[1:]) {
+				return CauseCollision, other.ID
+			}
+			if !alive && isCoordInList(head, trailing(other, constrictor)) {
+				return CauseCollision, other.ID
+			}
+		}
+
+		for _, other := range prev.Board.Snakes {
+			if other.ID == snake.ID || len(other.Body) == 0 {
+				continue
+			}
+			body, alive := after(other)
+			if alive && body[0] == head && length <= len(body) {
+				return CauseHeadToHead, other.ID
+			}
+
+			// A snake that went too could have met us anywhere next to it
+			if !alive && geo.Distance(other.Body[0], head) == 1 && length <= len(other.Body)+1 {
+				return CauseHeadToHead, other.ID
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// Main logic
+
+// Compare tells what happened between prev and next, two states of the same
+// game with next the later one. Moves and eliminations are inferred from the
+// boards alone, so they are exact for consecutive turns and guesses
+// otherwise.
+func Compare(prev, next *types.GameState) Diff {
+	diff := Diff{
+		From:           prev.Turn,
+		To:             next.Turn,
+		FoodEaten:      without(prev.Board.Food, next.Board.Food),
+		FoodSpawned:    without(next.Board.Food, prev.Board.Food),
+		HazardsAdded:   without(next.Board.Hazards, prev.Board.Hazards),
+		HazardsRemoved: without(prev.Board.Hazards, next.Board.Hazards),
+	}
+
+	geo := GeometryOf(next)
+	for _, before := range prev.Board.Snakes {
+		if len(before.Body) == 0 {
+			continue
+		}
+		change := SnakeChange{ID: before.ID}
+
+		after, ok := snakeByID(next, before.ID)
+		if !ok || len(after.Body) == 0 {
+			change.Health = -before.Health
+			change.Eliminated, change.By = inferElimination(prev, next, before)
+			if change.Eliminated == "" {
+				change.Eliminated = CauseUnknown
+			}
+			diff.Snakes = append(diff.Snakes, change)
+			continue
+		}
+
+		if geo.Distance(before.Body[0], after.Body[0]) == 1 {
+			change.Move = geo.Direction(before.Body[0], after.Body[0])
+		}
+		change.Ate = isCoordInList(after.Body[0], prev.Board.Food)
+		change.Health = after.Health - before.Health
+		change.Grew = len(after.Body) - len(before.Body)
+
+		diff.Snakes = append(diff.Snakes, change)
+	}
+
+	return diff
+}
@@ -0,0 +1,134 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name  string
+		state *types.GameState
+		moves map[string]types.Direction
+		want  []SnakeChange
+	}{
+		{
+			name: "moves, eats and grows",
+			state: withFood(game(Standard,
+				snake("a", 50, xy(1, 1), xy(1, 0), xy(0, 0)),
+				snake("b", 50, xy(5, 5), xy(5, 4), xy(5, 3)),
+			), xy(1, 2)),
+			moves: map[string]types.Direction{"a": types.Up, "b": types.Right},
+			want: []SnakeChange{
+				{ID: "a", Move: types.Up, Ate: true, Health: MaxHealth - 50, Grew: 1},
+				{ID: "b", Move: types.Right, Health: -1},
+			},
+		},
+		{
+			name:  "wall",
+			state: game(Standard, snake("a", 50, xy(0, 3), xy(1, 3), xy(2, 3))),
+			moves: map[string]types.Direction{"a": types.Left},
+			want:  []SnakeChange{{ID: "a", Health: -50, Eliminated: CauseOutOfBounds}},
+		},
+		{
+			name:  "out of health",
+			state: game(Standard, snake("a", 1, xy(3, 3), xy(3, 2), xy(3, 1))),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []SnakeChange{{ID: "a", Health: -1, Eliminated: CauseOutOfHealth}},
+		},
+		{
+			name: "self collision",
+			state: game(Standard,
+				snake("a", 50, xy(3, 3), xy(3, 2), xy(4, 2), xy(4, 3), xy(4, 4)),
+			),
+			moves: map[string]types.Direction{"a": types.Right},
+			want:  []SnakeChange{{ID: "a", Health: -50, Eliminated: CauseSelfCollision, By: "a"}},
+		},
+		{
+			name: "body collision",
+			state: game(Standard,
+				snake("a", 50, xy(2, 3), xy(1, 3), xy(0, 3)),
+				snake("b", 50, xy(3, 4), xy(3, 3), xy(3, 2), xy(3, 1)),
+			),
+			moves: map[string]types.Direction{"a": types.Right, "b": types.Up},
+			want: []SnakeChange{
+				{ID: "a", Health: -50, Eliminated: CauseCollision, By: "b"},
+				{ID: "b", Move: types.Up, Health: -1},
+			},
+		},
+		{
+			name: "head to head",
+			state: game(Standard,
+				snake("a", 50, xy(2, 3), xy(1, 3), xy(0, 3)),
+				snake("b", 50, xy(4, 3), xy(5, 3), xy(6, 3), xy(6, 4)),
+			),
+			moves: map[string]types.Direction{"a": types.Right, "b": types.Left},
+			want: []SnakeChange{
+				{ID: "a", Health: -50, Eliminated: CauseHeadToHead, By: "b"},
+				{ID: "b", Move: types.Left, Health: -1},
+			},
+		},
+		{
+			name: "no hazard damage",
+			state: withSettings(withHazards(game(Standard,
+				snake("a", 10, xy(3, 3), xy(3, 2), xy(3, 1)),
+				snake("b", 50, xy(4, 4), xy(3, 4), xy(2, 4), xy(1, 4)),
+			), xy(3, 4)), types.RulesetSettings{FoodSpawnChance: 15}),
+			moves: map[string]types.Direction{"a": types.Up, "b": types.Right},
+			want: []SnakeChange{
+				{ID: "a", Health: -10, Eliminated: CauseCollision, By: "b"},
+				{ID: "b", Move: types.Right, Health: -1},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := Next(test.state, test.moves, nil).State
+			diff := Compare(test.state, next)
+			if !reflect.DeepEqual(diff.Snakes, test.want) {
+				t.Errorf("got %+v, want %+v", diff.Snakes, test.want)
+			}
+		})
+	}
+}
+
+func TestCompareFood(t *testing.T) {
+	prev := withFood(game(Standard, snake("a", 50, xy(1, 1), xy(1, 0), xy(0, 0))), xy(1, 2), xy(5, 5))
+	next := Next(prev, map[string]types.Direction{"a": types.Up}, nil).State
+	next.Board.Food = append(next.Board.Food, xy(6, 6))
+
+	diff := Compare(prev, next)
+	if !reflect.DeepEqual(diff.FoodEaten, []types.Coord{xy(1, 2)}) {
+		t.Errorf("food eaten %v, want [(1, 2)]", diff.FoodEaten)
+	}
+	if !reflect.DeepEqual(diff.FoodSpawned, []types.Coord{xy(6, 6)}) {
+		t.Errorf("food spawned %v, want [(6, 6)]", diff.FoodSpawned)
+	}
+}
+
+func TestCompareEmptyBodies(t *testing.T) {
+	prev := game(Standard,
+		snake("a", 50, xy(2, 3), xy(1, 3), xy(0, 3)),
+		snake("b", 50, xy(3, 4), xy(3, 3), xy(3, 2)),
+		snake("c", 50),
+	)
+
+	// b is still listed in next but has lost its body
+	next := game(Standard,
+		snake("b", 49),
+		snake("c", 50),
+	)
+	next.Turn = 1
+
+	diff := Compare(prev, next)
+	want := []SnakeChange{
+		{ID: "a", Health: -50, Eliminated: CauseCollision, By: "b"},
+		{ID: "b", Health: -50, Eliminated: CauseHeadToHead, By: "a"},
+	}
+	if !reflect.DeepEqual(diff.Snakes, want) {
+		t.Errorf("got %+v, want %+v", diff.Snakes, want)
+	}
+}
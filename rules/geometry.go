@@ -0,0 +1,143 @@
+package rules
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Geometry answers board-shape questions for a given ruleset. On "wrapped"
+// boards the edges connect to the opposite side, everywhere else they are
+// walls.
+type Geometry struct {
+	Width   int
+	Height  int
+	Wrapped bool
+}
+
+func GeometryOf(state *types.GameState) Geometry {
+	return Geometry{
+		Width:   state.Board.Width,
+		Height:  state.Board.Height,
+		Wrapped: state.Game.Ruleset.Name == Wrapped,
+	}
+}
+
+// Move returns the cell reached from coord in the given direction. On
+// wrapped boards the result is brought back onto the board, otherwise it
+// may be out of bounds.
+func (g Geometry) Move(coord types.Coord, move types.Direction) types.Coord {
+	return g.Normalize(move.Apply(coord))
+}
+
+// Normalize maps coord back onto a wrapped board. It is a no-op otherwise.
+func (g Geometry) Normalize(coord types.Coord) types.Coord {
+	if !g.Wrapped || g.Width <= 0 || g.Height <= 0 {
+		return coord
+	}
+
+	coord.X = ((coord.X % g.Width) + g.Width) % g.Width
+	coord.Y = ((coord.Y % g.Height) + g.Height) % g.Height
+	return coord
+}
+
+func (g Geometry) InBounds(coord types.Coord) bool {
+	return coord.X >= 0 && coord.Y >= 0 && coord.X < g.Width && coord.Y < g.Height
+}
+
+// Neighbors returns the on-board cells adjacent to coord, in up, down, left,
+// right order.
+func (g Geometry) Neighbors(coord types.Coord) []types.Coord {
+	neighbors := make([]types.Coord, 0, 4)
+	for _, next := range coord.Neighbors() {
+		if next = g.Normalize(next); g.InBounds(next) {
+			neighbors = append(neighbors, next)
+		}
+	}
+	return neighbors
+}
+
+// Delta returns the shortest signed offset from a to b. On wrapped boards
+// going across an edge can be shorter than going through the middle.
+func (g Geometry) Delta(a, b types.Coord) (dx, dy int) {
+	dx = b.X - a.X
+	dy = b.Y - a.Y
+
+	if g.Wrapped {
+		dx = shortestOffset(dx, g.Width)
+		dy = shortestOffset(dy, g.Height)
+	}
+
+	return dx, dy
+}
+
+// Distance is the number of moves between a and b on an empty board.
+func (g Geometry) Distance(a, b types.Coord) int {
+	dx, dy := g.Delta(a, b)
+	return abs(dx) + abs(dy)
+}
+
+// Direction returns the move that brings a closer to b, preferring the
+// horizontal axis, or NoDirection if they are the same cell.
+func (g Geometry) Direction(a, b types.Coord) types.Direction {
+	dx, dy := g.Delta(a, b)
+	switch {
+	case dx > 0:
+		return types.Right
+	case dx < 0:
+		return types.Left
+	case dy > 0:
+		return types.Up
+	case dy < 0:
+		return types.Down
+	}
+	return types.NoDirection
+}
+
+// Reachable counts the cells that can be reached from start without going
+// through a blocked cell, start excluded. It stops counting at limit when
+// limit is positive.
+func (g Geometry) Reachable(start types.Coord, blocked func(types.Coord) bool, limit int) int {
+	seen := map[types.Coord]bool{start: true}
+	queue := []types.Coord{start}
+	count := 0
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range g.Neighbors(current) {
+			if seen[next] || blocked(next) {
+				continue
+			}
+			seen[next] = true
+			queue = append(queue, next)
+
+			count++
+			if limit > 0 && count >= limit {
+				return count
+			}
+		}
+	}
+
+	return count
+}
+
+func shortestOffset(d, size int) int {
+	if size <= 0 {
+		return d
+	}
+
+	d %= size
+	if d > size/2 {
+		d -= size
+	} else if d < -size/2 {
+		d += size
+	}
+	return d
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,171 @@
+package rules
+
+import (
+	"math/rand"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// HazardForecast predicts where hazards will be in a royale game. Every
+// ShrinkEveryNTurns turns the engine turns one random side of the safe area
+// into hazard. We cannot know which side, so the forecast treats the whole
+// outer ring of the safe area as hazard from the next shrink on, the next
+// ring from the one after that, and so on.
+type HazardForecast struct {
+	turn    int
+	every   int
+	width   int
+	height  int
+	hazards []types.Coord
+
+	// Bounding box of the cells that are not hazards yet
+	minX, minY, maxX, maxY int
+}
+
+func ForecastHazards(state *types.GameState) HazardForecast {
+	forecast := HazardForecast{
+		turn:    state.Turn,
+		every:   state.Game.Ruleset.Settings.Royale.ShrinkEveryNTurns,
+		width:   state.Board.Width,
+		height:  state.Board.Height,
+		hazards: state.Board.Hazards,
+	}
+
+	if state.Game.Ruleset.Name != Royale || forecast.every <= 0 {
+		forecast.every = 0
+		return forecast
+	}
+
+	forecast.minX, forecast.minY, forecast.maxX, forecast.maxY = safeArea(state)
+
+	return forecast
+}
+
+// Enabled reports whether hazards are expected to spread at all
+func (f HazardForecast) Enabled() bool {
+	return f.every > 0
+}
+
+// NextShrink returns the first turn after the forecast's turn on which the
+// safe area shrinks, or -1 when it never does.
+func (f HazardForecast) NextShrink() int {
+	if !f.Enabled() {
+		return -1
+	}
+	return (f.turn/f.every + 1) * f.every
+}
+
+// MayBeHazard reports whether coord could be a hazard on the given turn.
+// Cells that are already hazards are left to the caller to check.
+func (f HazardForecast) MayBeHazard(coord types.Coord, turn int) bool {
+	if !f.Enabled() {
+		return false
+	}
+
+	shrinks := turn/f.every - f.turn/f.every
+	if shrinks <= 0 {
+		return false
+	}
+
+	if f.minX > f.maxX || f.minY > f.maxY {
+		return true
+	}
+
+	// Distance to the closest edge of the safe area, 0 on the outer ring
+	ring := coord.X - f.minX
+	for _, d := range []int{f.maxX - coord.X, coord.Y - f.minY, f.maxY - coord.Y} {
+		if d < ring {
+			ring = d
+		}
+	}
+	return ring < shrinks
+}
+
+// Hazards returns the current hazards plus every cell that may have become
+// one by the given turn.
+func (f HazardForecast) Hazards(turn int) []types.Coord {
+	hazards := append([]types.Coord(nil), f.hazards...)
+	if !f.Enabled() {
+		return hazards
+	}
+
+	known := make(map[types.Coord]bool, len(f.hazards))
+	for _, hazard := range f.hazards {
+		known[hazard] = true
+	}
+
+	for y := 0; y < f.height; y++ {
+		for x := 0; x < f.width; x++ {
+			coord := types.Coord{X: x, Y: y}
+			if !known[coord] && f.MayBeHazard(coord, turn) {
+				hazards = append(hazards, coord)
+			}
+		}
+	}
+
+	return hazards
+}
+
+func safeArea(state *types.GameState) (minX, minY, maxX, maxY int) {
+	hazards := make(map[types.Coord]bool, len(state.Board.Hazards))
+	for _, hazard := range state.Board.Hazards {
+		hazards[hazard] = true
+	}
+
+	minX, minY = state.Board.Width, state.Board.Height
+	maxX, maxY = -1, -1
+	for y := 0; y < state.Board.Height; y++ {
+		for x := 0; x < state.Board.Width; x++ {
+			if hazards[types.Coord{X: x, Y: y}] {
+				continue
+			}
+			if x < minX {
+				minX = x
+			}
+			if x > maxX {
+				maxX = x
+			}
+			if y < minY {
+				minY = y
+			}
+			if y > maxY {
+				maxY = y
+			}
+		}
+	}
+
+	return minX, minY, maxX, maxY
+}
+
+// shrinkSafeArea turns one random side of the safe area into hazard when a
+// royale game reaches a shrink turn.
+func shrinkSafeArea(state *types.GameState, rng *rand.Rand) {
+	every := state.Game.Ruleset.Settings.Royale.ShrinkEveryNTurns
+	if every <= 0 || state.Turn == 0 || state.Turn%every != 0 {
+		return
+	}
+
+	minX, minY, maxX, maxY := safeArea(state)
+	if minX > maxX || minY > maxY {
+		return
+	}
+
+	switch rng.Intn(4) {
+	case 0:
+		for y := minY; y <= maxY; y++ {
+			state.Board.Hazards = append(state.Board.Hazards, types.Coord{X: minX, Y: y})
+		}
+	case 1:
+		for y := minY; y <= maxY; y++ {
+			state.Board.Hazards = append(state.Board.Hazards, types.Coord{X: maxX, Y: y})
+		}
+	case 2:
+		for x := minX; x <= maxX; x++ {
+			state.Board.Hazards = append(state.Board.Hazards, types.Coord{X: x, Y: minY})
+		}
+	case 3:
+		for x := minX; x <= maxX; x++ {
+			state.Board.Hazards = append(state.Board.Hazards, types.Coord{X: x, Y: maxY})
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package rules
+
+import (
+	"math/rand"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Ruleset names as sent in Game.Ruleset.Name
+// https://docs.battlesnake.com/guides/game/rules
+const (
+	Standard    = "standard"
+	Solo        = "solo"
+	Royale      = "royale"
+	Wrapped     = "wrapped"
+	Constrictor = "constrictor"
+)
+
+const (
+	MaxHealth = 100
+
+	// Used when the engine sends no ruleset settings at all
+	DefaultHazardDamage = 14
+)
+
+type EliminationCause string
+
+const (
+	CauseOutOfHealth   EliminationCause = "out-of-health"
+	CauseOutOfBounds   EliminationCause = "wall-collision"
+	CauseSelfCollision EliminationCause = "snake-self-collision"
+	CauseCollision     EliminationCause = "snake-collision"
+	CauseHeadToHead    EliminationCause = "head-collision"
+)
+
+type Elimination struct {
+	SnakeID string
+	Cause   EliminationCause
+	By      string // ID of the other snake involved, if any
+}
+
+type Result struct {
+	State      *types.GameState
+	Eliminated []Elimination
+}
+
+// Next advances state by one turn and returns the resulting state, leaving
+// the original untouched. moves maps snake IDs to their move; snakes
+// without a valid move keep going in their current direction.
+// Food is only spawned when rng is not nil.
+func Next(state *types.GameState, moves map[string]types.Direction, rng *rand.Rand) Result {
+	next := Clone(state)
+	next.Turn++
+
+	moveSnakes(next, moves)
+	reduceHealth(next)
+	damageHazards(next)
+	feedSnakes(next)
+	if next.Game.Ruleset.Name == Constrictor {
+		growSnakes(next)
+	} else if rng != nil {
+		spawnFood(next, rng)
+	}
+	if next.Game.Ruleset.Name == Royale && rng != nil {
+		shrinkSafeArea(next, rng)
+	}
+	eliminated := eliminateSnakes(next)
+
+	next.You = findSnake(next, state.You.ID, next.You)
+
+	return Result{
+		State:      next,
+		Eliminated: eliminated,
+	}
+}
+
+func moveSnakes(state *types.GameState, moves map[string]types.Direction) {
+	geo := GeometryOf(state)
+
+	for i := range state.Board.Snakes {
+		snake := &state.Board.Snakes[i]
+		if len(snake.Body) == 0 {
+			continue
+		}
+
+		move := moves[snake.ID]
+		if !move.Valid() {
+			move = currentDirection(geo, snake.Body)
+		}
+
+		newHead := geo.Move(snake.Body[0], move)
+		copy(snake.Body[1:], snake.Body[:len(snake.Body)-1])
+		snake.Body[0] = newHead
+		snake.Head = newHead
+		snake.Length = len(snake.Body)
+	}
+}
+
+func reduceHealth(state *types.GameState) {
+	for i := range state.Board.Snakes {
+		state.Board.Snakes[i].Health--
+	}
+}
+
+func damageHazards(state *types.GameState) {
+	damage := HazardDamage(state)
+
+	for i := range state.Board.Snakes {
+		snake := &state.Board.Snakes[i]
+
+		// Eating cancels hazard damage for this turn
+		if isCoordInList(snake.Head, state.Board.Food) {
+			continue
+		}
+
+		// Stacked hazards apply their damage once per stack
+		for _, hazard := range state.Board.Hazards {
+			if hazard == snake.Head {
+				snake.Health -= damage
+			}
+		}
+
+		if snake.Health < 0 {
+			snake.Health = 0
+		}
+	}
+}
+
+func feedSnakes(state *types.GameState) {
+	eaten := make(map[types.Coord]bool)
+
+	for i := range state.Board.Snakes {
+		snake := &state.Board.Snakes[i]
+		if len(snake.Body) == 0 || !isCoordInList(snake.Head, state.Board.Food) {
+			continue
+		}
+
+		snake.Health = MaxHealth
+		snake.Body = append(snake.Body, snake.Body[len(snake.Body)-1])
+		snake.Length = len(snake.Body)
+		eaten[snake.Head] = true
+	}
+
+	if len(eaten) == 0 {
+		return
+	}
+
+	food := state.Board.Food[:0]
+	for _, f := range state.Board.Food {
+		if !eaten[f] {
+			food = append(food, f)
+		}
+	}
+	state.Board.Food = food
+}
+
+// growSnakes implements constrictor mode, where there is no food and every
+// snake grows and stays at full health on every turn.
+func growSnakes(state *types.GameState) {
+	state.Board.Food = state.Board.Food[:0]
+
+	for i := range state.Board.Snakes {
+		snake := &state.Board.Snakes[i]
+		if len(snake.Body) == 0 {
+			continue
+		}
+		snake.Health = MaxHealth
+		snake.Body = append(snake.Body, snake.Body[len(snake.Body)-1])
+		snake.Length = len(snake.Body)
+	}
+}
+
+// HazardDamage is the health a snake loses per hazard on its head. A game
+// that sets its ruleset settings means the damage it sets, even 0. Only
+// without any settings, as in a state put together by hand, is the damage
+// the engine's default.
+func HazardDamage(state *types.GameState) int {
+	settings := state.Game.Ruleset.Settings
+	if settings == (types.RulesetSettings{}) {
+		return DefaultHazardDamage
+	}
+	return settings.HazardDamagePerTurn
+}
+
+// TailMoves reports whether tails follow the head on the next turn. In
+// constrictor games they never do, so a tail cell never frees up.
+func TailMoves(state *types.GameState) bool {
+	return state.Game.Ruleset.Name != Constrictor
+}
+
+func spawnFood(state *types.GameState, rng *rand.Rand) {
+	settings := state.Game.Ruleset.Settings
+
+	count := 0
+	if len(state.Board.Food) < settings.MinimumFood {
+		count = settings.MinimumFood - len(state.Board.Food)
+	} else if settings.FoodSpawnChance > 0 && rng.Intn(100) < settings.FoodSpawnChance {
+		count = 1
+	}
+
+	for ; count > 0; count-- {
+		free := unoccupiedCells(state)
+		if len(free) == 0 {
+			return
+		}
+		state.Board.Food = append(state.Board.Food, free[rng.Intn(len(free))])
+	}
+}
+
+func unoccupiedCells(state *types.GameState) []types.Coord {
+	occupied := make(map[types.Coord]bool)
+	for _, food := range state.Board.Food {
+		occupied[food] = true
+	}
+	for _, snake := range state.Board.Snakes {
+		for _, segment := range snake.Body {
+			occupied[segment] = true
+		}
+	}
+
+	free := make([]types.Coord, 0, state.Board.Width*state.Board.Height-len(occupied))
+	for y := 0; y < state.Board.Height; y++ {
+		for x := 0; x < state.Board.Width; x++ {
+			coord := types.Coord{X: x, Y: y}
+			if !occupied[coord] {
+				free = append(free, coord)
+			}
+		}
+	}
+	return free
+}
+
+func eliminateSnakes(state *types.GameState) []Elimination {
+	geo := GeometryOf(state)
+	eliminated := []Elimination{}
+	dead := make(map[string]bool)
+
+	// Starvation and walls are resolved first so that snakes dying this way
+	// cannot take anyone else down with them. Snakes without a body never
+	// moved, only starvation applies to them.
+	for _, snake := range state.Board.Snakes {
+		switch {
+		case snake.Health <= 0:
+			eliminated = append(eliminated, Elimination{SnakeID: snake.ID, Cause: CauseOutOfHealth})
+			dead[snake.ID] = true
+		case len(snake.Body) > 0 && !geo.InBounds(snake.Head):
+			eliminated = append(eliminated, Elimination{SnakeID: snake.ID, Cause: CauseOutOfBounds})
+			dead[snake.ID] = true
+		}
+	}
+
+	// Collisions are checked against every survivor of the first pass
+	collisions := []Elimination{}
+	for _, snake := range state.Board.Snakes {
+		if dead[snake.ID] {
+			continue
+		}
+		if elimination, ok := collision(state, snake, dead); ok {
+			collisions = append(collisions, elimination)
+		}
+	}
+
+	for _, elimination := range collisions {
+		dead[elimination.SnakeID] = true
+	}
+	eliminated = append(eliminated, collisions...)
+
+	alive := state.Board.Snakes[:0]
+	for _, snake := range state.Board.Snakes {
+		if !dead[snake.ID] {
+			alive = append(alive, snake)
+		}
+	}
+	state.Board.Snakes = alive
+
+	return eliminated
+}
+
+func collision(state *types.GameState, snake types.Battlesnake, dead map[string]bool) (Elimination, bool) {
+	if len(snake.Body) == 0 {
+		return Elimination{}, false
+	}
+
+	if isCoordInList(snake.Head, snake.Body[1:]) {
+		return Elimination{SnakeID: snake.ID, Cause: CauseSelfCollision, By: snake.ID}, true
+	}
+
+	for _, other := range state.Board.Snakes {
+		if other.ID == snake.ID || dead[other.ID] || len(other.Body) == 0 {
+			continue
+		}
+		if isCoordInList(snake.Head, other.Body[1:]) {
+			return Elimination{SnakeID: snake.ID, Cause: CauseCollision, By: other.ID}, true
+		}
+	}
+
+	for _, other := range state.Board.Snakes {
+		if other.ID == snake.ID || dead[other.ID] || len(other.Body) == 0 {
+			continue
+		}
+		if snake.Head == other.Head && len(snake.Body) <= len(other.Body) {
+			return Elimination{SnakeID: snake.ID, Cause: CauseHeadToHead, By: other.ID}, true
+		}
+	}
+
+	return Elimination{}, false
+}
+
+// Helper functions
+
+func currentDirection(geo Geometry, body []types.Coord) types.Direction {
+	if len(body) < 2 {
+		return types.Up
+	}
+
+	if move := geo.Direction(body[1], body[0]); move.Valid() {
+		return move
+	}
+	return types.Up
+}
+
+func isCoordInList(coord types.Coord, list []types.Coord) bool {
+	for _, c := range list {
+		if c == coord {
+			return true
+		}
+	}
+	return false
+}
+
+func findSnake(state *types.GameState, id string, fallback types.Battlesnake) types.Battlesnake {
+	for _, snake := range state.Board.Snakes {
+		if snake.ID == id {
+			return snake
+		}
+	}
+
+	// Eliminated snakes keep their final position with no health left
+	fallback.Health = 0
+	return fallback
+}
+
+// Clone is a deep copy of state. Unlike GameState.Copy it keeps hazards and
+// every snake field, which the simulation needs.
+func Clone(state *types.GameState) *types.GameState {
+	copied := *state
+	copied.Board.Food = append([]types.Coord(nil), state.Board.Food...)
+	copied.Board.Hazards = append([]types.Coord(nil), state.Board.Hazards...)
+	copied.Board.Snakes = make([]types.Battlesnake, len(state.Board.Snakes))
+	for i, snake := range state.Board.Snakes {
+		snake.Body = append([]types.Coord(nil), snake.Body...)
+		copied.Board.Snakes[i] = snake
+	}
+	copied.You.Body = append([]types.Coord(nil), state.You.Body...)
+	return &copied
+}
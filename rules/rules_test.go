@@ -0,0 +1,342 @@
+package rules
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Helper functions
+
+func snake(id string, health int, body ...types.Coord) types.Battlesnake {
+	s := types.Battlesnake{ID: id, Name: id, Health: health, Body: body, Length: len(body)}
+	if len(body) > 0 {
+		s.Head = body[0]
+	}
+	return s
+}
+
+func xy(x, y int) types.Coord {
+	return types.Coord{X: x, Y: y}
+}
+
+func game(ruleset string, snakes ...types.Battlesnake) *types.GameState {
+	state := &types.GameState{
+		Game: types.Game{
+			ID:      "test",
+			Ruleset: types.Ruleset{Name: ruleset},
+		},
+		Board: types.Board{
+			Width:  7,
+			Height: 7,
+			Snakes: snakes,
+		},
+	}
+	if len(snakes) > 0 {
+		state.You = snakes[0]
+	}
+	return state
+}
+
+func withFood(state *types.GameState, food ...types.Coord) *types.GameState {
+	state.Board.Food = food
+	return state
+}
+
+func withHazards(state *types.GameState, hazards ...types.Coord) *types.GameState {
+	state.Board.Hazards = hazards
+	return state
+}
+
+func withSettings(state *types.GameState, settings types.RulesetSettings) *types.GameState {
+	state.Game.Ruleset.Settings = settings
+	return state
+}
+
+func findResult(result Result, id string) (types.Battlesnake, Elimination, bool) {
+	for _, s := range result.State.Board.Snakes {
+		if s.ID == id {
+			return s, Elimination{}, true
+		}
+	}
+	for _, e := range result.Eliminated {
+		if e.SnakeID == id {
+			return types.Battlesnake{}, e, false
+		}
+	}
+	return types.Battlesnake{}, Elimination{}, false
+}
+
+// Tests
+
+func TestNext(t *testing.T) {
+	type want struct {
+		id     string
+		alive  bool
+		head   types.Coord
+		health int
+		length int
+		cause  EliminationCause
+		by     string
+	}
+
+	tests := []struct {
+		name  string
+		state *types.GameState
+		moves map[string]types.Direction
+		want  []want
+	}{
+		{
+			name:  "moves and loses health",
+			state: game(Standard, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1))),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", alive: true, head: xy(3, 4), health: 49, length: 3}},
+		},
+		{
+			name:  "keeps going straight without a move",
+			state: game(Standard, snake("a", 50, xy(3, 3), xy(2, 3), xy(1, 3))),
+			want:  []want{{id: "a", alive: true, head: xy(4, 3), health: 49, length: 3}},
+		},
+		{
+			name:  "eats and grows",
+			state: withFood(game(Standard, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1))), xy(3, 4)),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", alive: true, head: xy(3, 4), health: MaxHealth, length: 4}},
+		},
+		{
+			name:  "hazard damage",
+			state: withHazards(game(Standard, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1))), xy(3, 4)),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", alive: true, head: xy(3, 4), health: 50 - 1 - DefaultHazardDamage, length: 3}},
+		},
+		{
+			name:  "stacked hazards deal damage once per stack",
+			state: withHazards(game(Standard, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1))), xy(3, 4), xy(3, 4)),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", alive: true, head: xy(3, 4), health: 50 - 1 - 2*DefaultHazardDamage, length: 3}},
+		},
+		{
+			name:  "hazard damage set by the game",
+			state: withSettings(withHazards(game(Standard, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1))), xy(3, 4)), types.RulesetSettings{HazardDamagePerTurn: 7}),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", alive: true, head: xy(3, 4), health: 50 - 1 - 7, length: 3}},
+		},
+		{
+			name:  "no hazard damage",
+			state: withSettings(withHazards(game(Standard, snake("a", 10, xy(3, 3), xy(3, 2), xy(3, 1))), xy(3, 4)), types.RulesetSettings{FoodSpawnChance: 15}),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", alive: true, head: xy(3, 4), health: 9, length: 3}},
+		},
+		{
+			name:  "hazard damage kills before food is reached",
+			state: withHazards(game(Standard, snake("a", 10, xy(3, 3), xy(3, 2), xy(3, 1))), xy(3, 4)),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", cause: CauseOutOfHealth}},
+		},
+		{
+			name:  "eating cancels hazard damage",
+			state: withFood(withHazards(game(Standard, snake("a", 10, xy(3, 3), xy(3, 2), xy(3, 1))), xy(3, 4)), xy(3, 4)),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", alive: true, head: xy(3, 4), health: MaxHealth, length: 4}},
+		},
+		{
+			name:  "starvation",
+			state: game(Standard, snake("a", 1, xy(3, 3), xy(3, 2), xy(3, 1))),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", cause: CauseOutOfHealth}},
+		},
+		{
+			name:  "food saves a starving snake",
+			state: withFood(game(Standard, snake("a", 1, xy(3, 3), xy(3, 2), xy(3, 1))), xy(3, 4)),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", alive: true, head: xy(3, 4), health: MaxHealth, length: 4}},
+		},
+		{
+			name:  "wall",
+			state: game(Standard, snake("a", 50, xy(3, 6), xy(3, 5), xy(3, 4))),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", cause: CauseOutOfBounds}},
+		},
+		{
+			name:  "self collision",
+			state: game(Standard, snake("a", 50, xy(3, 3), xy(3, 2), xy(4, 2), xy(4, 3), xy(4, 4))),
+			moves: map[string]types.Direction{"a": types.Right},
+			want:  []want{{id: "a", cause: CauseSelfCollision, by: "a"}},
+		},
+		{
+			name:  "own tail moves out of the way",
+			state: game(Standard, snake("a", 50, xy(3, 3), xy(3, 2), xy(4, 2), xy(4, 3))),
+			moves: map[string]types.Direction{"a": types.Right},
+			want:  []want{{id: "a", alive: true, head: xy(4, 3), health: 49, length: 4}},
+		},
+		{
+			name: "body collision",
+			state: game(Standard,
+				snake("a", 50, xy(2, 3), xy(1, 3), xy(0, 3)),
+				snake("b", 50, xy(3, 4), xy(3, 3), xy(3, 2), xy(3, 1))),
+			moves: map[string]types.Direction{"a": types.Right, "b": types.Up},
+			want: []want{
+				{id: "a", cause: CauseCollision, by: "b"},
+				{id: "b", alive: true, head: xy(3, 5), health: 49, length: 4},
+			},
+		},
+		{
+			name: "head to head tie",
+			state: game(Standard,
+				snake("a", 50, xy(2, 3), xy(1, 3), xy(0, 3)),
+				snake("b", 50, xy(4, 3), xy(5, 3), xy(6, 3))),
+			moves: map[string]types.Direction{"a": types.Right, "b": types.Left},
+			want: []want{
+				{id: "a", cause: CauseHeadToHead, by: "b"},
+				{id: "b", cause: CauseHeadToHead, by: "a"},
+			},
+		},
+		{
+			name: "head to head loss",
+			state: game(Standard,
+				snake("a", 50, xy(2, 3), xy(1, 3), xy(0, 3)),
+				snake("b", 50, xy(4, 3), xy(5, 3), xy(6, 3), xy(6, 4))),
+			moves: map[string]types.Direction{"a": types.Right, "b": types.Left},
+			want: []want{
+				{id: "a", cause: CauseHeadToHead, by: "b"},
+				{id: "b", alive: true, head: xy(3, 3), health: 49, length: 4},
+			},
+		},
+		{
+			name: "snakes starving do not take others down",
+			state: game(Standard,
+				snake("a", 50, xy(2, 3), xy(1, 3), xy(0, 3)),
+				snake("b", 1, xy(4, 3), xy(5, 3), xy(6, 3))),
+			moves: map[string]types.Direction{"a": types.Right, "b": types.Left},
+			want: []want{
+				{id: "a", alive: true, head: xy(3, 3), health: 49, length: 3},
+				{id: "b", cause: CauseOutOfHealth},
+			},
+		},
+		{
+			name:  "constrictor grows every turn",
+			state: withFood(game(Constrictor, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1))), xy(0, 0)),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", alive: true, head: xy(3, 4), health: MaxHealth, length: 4}},
+		},
+		{
+			name:  "wrapped boards have no walls",
+			state: game(Wrapped, snake("a", 50, xy(3, 6), xy(3, 5), xy(3, 4))),
+			moves: map[string]types.Direction{"a": types.Up},
+			want:  []want{{id: "a", alive: true, head: xy(3, 0), health: 49, length: 3}},
+		},
+		{
+			name:  "snakes without a body",
+			state: game(Standard, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1)), snake("b", 50)),
+			moves: map[string]types.Direction{"a": types.Up},
+			want: []want{
+				{id: "a", alive: true, head: xy(3, 4), health: 49, length: 3},
+				{id: "b", alive: true, health: 49},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := Clone(tt.state)
+			result := Next(tt.state, tt.moves, nil)
+
+			for _, w := range tt.want {
+				s, e, alive := findResult(result, w.id)
+				if alive != w.alive {
+					t.Fatalf("%s alive = %v, want %v (eliminated: %+v)", w.id, alive, w.alive, result.Eliminated)
+				}
+				if !alive {
+					if e.Cause != w.cause || (w.by != "" && e.By != w.by) {
+						t.Errorf("%s eliminated by %s (%s), want %s (%s)", w.id, e.By, e.Cause, w.by, w.cause)
+					}
+					continue
+				}
+				if len(s.Body) > 0 && s.Head != w.head {
+					t.Errorf("%s head = %v, want %v", w.id, s.Head, w.head)
+				}
+				if s.Health != w.health {
+					t.Errorf("%s health = %d, want %d", w.id, s.Health, w.health)
+				}
+				if len(s.Body) != w.length {
+					t.Errorf("%s length = %d, want %d", w.id, len(s.Body), w.length)
+				}
+			}
+
+			if tt.state.Board.Snakes[0].Health != before.Board.Snakes[0].Health || tt.state.Turn != before.Turn {
+				t.Error("Next changed the original state")
+			}
+		})
+	}
+}
+
+func TestNextFood(t *testing.T) {
+	t.Run("eaten food is gone", func(t *testing.T) {
+		state := withFood(game(Standard, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1))), xy(3, 4), xy(0, 0))
+		result := Next(state, map[string]types.Direction{"a": types.Up}, nil)
+		if len(result.State.Board.Food) != 1 || result.State.Board.Food[0] != xy(0, 0) {
+			t.Errorf("food = %v, want [(0,0)]", result.State.Board.Food)
+		}
+	})
+
+	t.Run("food spawns after eating, on a free cell", func(t *testing.T) {
+		state := withFood(game(Standard, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1))), xy(3, 4))
+		state.Game.Ruleset.Settings.MinimumFood = 1
+		result := Next(state, map[string]types.Direction{"a": types.Up}, rand.New(rand.NewSource(1)))
+
+		s, _, _ := findResult(result, "a")
+		if len(s.Body) != 4 {
+			t.Fatalf("length = %d, want 4: food must be eaten before spawning", len(s.Body))
+		}
+		if len(result.State.Board.Food) != 1 {
+			t.Fatalf("food = %v, want one spawned", result.State.Board.Food)
+		}
+		if isCoordInList(result.State.Board.Food[0], s.Body) {
+			t.Errorf("food spawned on the snake at %v", result.State.Board.Food[0])
+		}
+	})
+
+	t.Run("no spawn without rng", func(t *testing.T) {
+		state := game(Standard, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1)))
+		state.Game.Ruleset.Settings.MinimumFood = 3
+		result := Next(state, map[string]types.Direction{"a": types.Up}, nil)
+		if len(result.State.Board.Food) != 0 {
+			t.Errorf("food = %v, want none", result.State.Board.Food)
+		}
+	})
+
+	t.Run("constrictor clears food", func(t *testing.T) {
+		state := withFood(game(Constrictor, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1))), xy(0, 0))
+		state.Game.Ruleset.Settings.MinimumFood = 1
+		result := Next(state, map[string]types.Direction{"a": types.Up}, rand.New(rand.NewSource(1)))
+		if len(result.State.Board.Food) != 0 {
+			t.Errorf("food = %v, want none", result.State.Board.Food)
+		}
+	})
+}
+
+func TestNextRoyale(t *testing.T) {
+	for _, ruleset := range []string{Royale, Standard} {
+		t.Run(ruleset, func(t *testing.T) {
+			state := game(ruleset, snake("a", 50, xy(3, 3), xy(3, 2), xy(3, 1)))
+			state.Turn = 4
+			state.Game.Ruleset.Settings.Royale.ShrinkEveryNTurns = 5
+			result := Next(state, map[string]types.Direction{"a": types.Up}, rand.New(rand.NewSource(1)))
+
+			want := 0
+			if ruleset == Royale {
+				want = state.Board.Width
+			}
+			if got := len(result.State.Board.Hazards); got != want {
+				t.Errorf("hazards after shrink turn = %d, want %d", got, want)
+			}
+
+			// Not a shrink turn
+			result = Next(result.State, map[string]types.Direction{"a": types.Up}, rand.New(rand.NewSource(1)))
+			if got := len(result.State.Board.Hazards); got != want {
+				t.Errorf("hazards the turn after = %d, want %d", got, want)
+			}
+		})
+	}
+}
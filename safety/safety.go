@@ -0,0 +1,154 @@
+package safety
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/shout"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Board size assumed when the state does not say, the standard one
+const defaultSize = 11
+
+// Helper functions
+
+// head finds our snake's head in a state that may be missing parts
+func head(state *types.GameState) (types.Coord, bool) {
+	if len(state.You.Body) > 0 {
+		return state.You.Body[0], true
+	}
+
+	for _, snake := range state.Board.Snakes {
+		if snake.ID == state.You.ID && len(snake.Body) > 0 {
+			return snake.Body[0], true
+		}
+	}
+
+	// Without knowing which snake is ours, a snake alone on the board can
+	// only be us
+	if snakes := state.Board.Snakes; len(snakes) == 1 && len(snakes[0].Body) > 0 {
+		return snakes[0].Body[0], true
+	}
+
+	return types.Coord{}, false
+}
+
+// occupied returns every cell a snake will still be on next turn, so all of
+// the bodies but the tails that are sure to move
+func occupied(state *types.GameState) map[types.Coord]bool {
+	cells := make(map[types.Coord]bool)
+
+	snakes := state.Board.Snakes
+	if len(snakes) == 0 {
+		snakes = []types.Battlesnake{state.You}
+	}
+
+	for _, snake := range snakes {
+		body := snake.Body
+		if n := len(body); n > 1 && body[n-1] != body[n-2] && rules.TailMoves(state) {
+			body = body[:n-1]
+		}
+		for _, coord := range body {
+			cells[coord] = true
+		}
+	}
+
+	return cells
+}
+
+// contested returns the cells a snake at least as long as ours can move its
+// head to
+func contested(state *types.GameState, geo rules.Geometry) map[types.Coord]bool {
+	cells := make(map[types.Coord]bool)
+
+	for _, snake := range state.Board.Snakes {
+		if snake.ID == state.You.ID || len(snake.Body) == 0 || len(snake.Body) < len(state.You.Body) {
+			continue
+		}
+		for _, next := range snake.Body[0].Neighbors() {
+			cells[geo.Normalize(next)] = true
+		}
+	}
+
+	return cells
+}
+
+// geometry of state, with the standard board when the state has no size
+func geometry(state *types.GameState) rules.Geometry {
+	geo := rules.GeometryOf(state)
+	if geo.Width <= 0 || geo.Height <= 0 {
+		geo.Width, geo.Height = defaultSize, defaultSize
+	}
+	return geo
+}
+
+// Main logic
+
+// Room returns, by move, the number of cells left to move in once the move
+// is made, the cell moved to included. Moves that run into a wall or a body
+// are left out, and the room after a head-to-head we could lose is cut to a
+// quarter.
+func Room(state *types.GameState) map[types.Direction]int {
+	room := make(map[types.Direction]int, 4)
+
+	start, ok := head(state)
+	if !ok {
+		return room
+	}
+
+	geo := geometry(state)
+	blocked := occupied(state)
+	risky := contested(state, geo)
+	isBlocked := func(coord types.Coord) bool {
+		return blocked[coord]
+	}
+
+	for _, move := range types.AllDirections() {
+		next := geo.Move(start, move)
+		if !geo.InBounds(next) || blocked[next] {
+			continue
+		}
+
+		score := geo.Reachable(next, isBlocked, 0) + 1
+		if risky[next] {
+			score /= 4
+		}
+		room[move] = score
+	}
+
+	return room
+}
+
+// Move is the move of last resort, for when there is no strategy to ask or
+// the strategy failed. It copes with whatever part of the state could be
+// read and picks the move with the most room that does not run into a wall
+// or a body, keeping away from head-to-heads it could lose.
+func Move(state *types.GameState) types.BattlesnakeMoveResponse {
+	start, ok := head(state)
+	if !ok {
+		return types.BattlesnakeMoveResponse{Move: types.Up.String(), Shout: shout.Fallback("lost track of my head")}
+	}
+
+	room := Room(state)
+
+	bestMove := types.NoDirection
+	bestScore := -1
+	for _, move := range types.AllDirections() {
+		if score, ok := room[move]; ok && score > bestScore {
+			bestScore = score
+			bestMove = move
+		}
+	}
+
+	if !bestMove.Valid() {
+		// Nothing is safe, at least stay on the board
+		geo := geometry(state)
+		for _, move := range types.AllDirections() {
+			if geo.InBounds(geo.Move(start, move)) {
+				return types.BattlesnakeMoveResponse{Move: move.String(), Shout: shout.Trapped(0)}
+			}
+		}
+		return types.BattlesnakeMoveResponse{Move: types.Up.String(), Shout: shout.Trapped(0)}
+	}
+
+	return types.BattlesnakeMoveResponse{Move: bestMove.String(), Shout: shout.Room(bestScore)}
+}
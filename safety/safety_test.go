@@ -0,0 +1,162 @@
+package safety
+
+import (
+	"testing"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Helper functions
+
+func snake(id string, body ...types.Coord) types.Battlesnake {
+	s := types.Battlesnake{ID: id, Health: 90, Body: body, Length: len(body)}
+	if len(body) > 0 {
+		s.Head = body[0]
+	}
+	return s
+}
+
+func xy(x, y int) types.Coord {
+	return types.Coord{X: x, Y: y}
+}
+
+// board puts you and the others on a 7x7 board
+func board(you types.Battlesnake, others ...types.Battlesnake) *types.GameState {
+	return &types.GameState{
+		Game:  types.Game{Ruleset: types.Ruleset{Name: "standard"}},
+		Board: types.Board{Width: 7, Height: 7, Snakes: append([]types.Battlesnake{you}, others...)},
+		You:   you,
+	}
+}
+
+// Tests
+
+func TestMove(t *testing.T) {
+	us := "us"
+
+	tests := []struct {
+		name  string
+		state *types.GameState
+		want  []types.Direction // Any of them will do
+	}{
+		{
+			name:  "wall",
+			state: board(snake(us, xy(0, 3), xy(1, 3), xy(2, 3))),
+			want:  []types.Direction{types.Up, types.Down},
+		},
+		{
+			name:  "corner",
+			state: board(snake(us, xy(0, 0), xy(1, 0), xy(2, 0))),
+			want:  []types.Direction{types.Up},
+		},
+		{
+			name:  "own body",
+			state: board(snake(us, xy(0, 1), xy(1, 1), xy(1, 2), xy(0, 2), xy(0, 3))),
+			want:  []types.Direction{types.Down},
+		},
+		{
+			name: "other bodies",
+			state: board(
+				snake(us, xy(3, 3), xy(3, 2), xy(3, 1), xy(3, 0), xy(4, 0), xy(5, 0)),
+				snake("them", xy(4, 4), xy(3, 4), xy(2, 4), xy(2, 3), xy(2, 2)),
+			),
+			want: []types.Direction{types.Right},
+		},
+		{
+			name: "tails move away",
+			state: board(
+				snake(us, xy(0, 0), xy(1, 0), xy(2, 0)),
+				snake("them", xy(1, 3), xy(1, 2), xy(0, 2), xy(0, 1)),
+			),
+			want: []types.Direction{types.Up},
+		},
+		{
+			name: "head to head against a longer snake",
+			state: board(
+				snake(us, xy(3, 3), xy(3, 2), xy(3, 1)),
+				snake("them", xy(5, 3), xy(6, 3), xy(6, 2), xy(6, 1)),
+			),
+			want: []types.Direction{types.Up, types.Left},
+		},
+		{
+			name: "trapped stays on the board",
+			state: board(
+				snake(us, xy(0, 0), xy(0, 0), xy(0, 0)),
+				snake("them", xy(2, 1), xy(1, 1), xy(0, 1), xy(1, 0), xy(2, 0), xy(3, 0)),
+			),
+			want: []types.Direction{types.Up, types.Right},
+		},
+		{
+			name: "no board size",
+			state: &types.GameState{
+				You: snake(us, xy(0, 0), xy(1, 0)),
+			},
+			want: []types.Direction{types.Up},
+		},
+		{
+			name: "you only on the board",
+			state: &types.GameState{
+				Board: types.Board{Width: 7, Height: 7, Snakes: []types.Battlesnake{snake(us, xy(6, 6), xy(5, 6))}},
+				You:   types.Battlesnake{ID: us},
+			},
+			want: []types.Direction{types.Down},
+		},
+		{
+			name: "a snake alone on the board",
+			state: &types.GameState{
+				Board: types.Board{Width: 7, Height: 7, Snakes: []types.Battlesnake{snake("who", xy(6, 0), xy(6, 1), xy(6, 2))}},
+			},
+			want: []types.Direction{types.Left},
+		},
+		{
+			name:  "nothing to go on",
+			state: &types.GameState{},
+			want:  []types.Direction{types.Up},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := Move(test.state)
+
+			move, err := types.ParseDirection(response.Move)
+			if err != nil {
+				t.Fatalf("moved %q", response.Move)
+			}
+			for _, want := range test.want {
+				if move == want {
+					return
+				}
+			}
+			t.Errorf("moved %s, want one of %v", move, test.want)
+		})
+	}
+}
+
+func TestRoom(t *testing.T) {
+	state := board(
+		snake("us", xy(3, 3), xy(3, 2), xy(3, 1)),
+		snake("them", xy(5, 3), xy(6, 3), xy(6, 2), xy(6, 1)),
+	)
+
+	room := Room(state)
+	if _, ok := room[types.Down]; ok {
+		t.Error("room for a move into our own neck")
+	}
+
+	// Right could meet the longer snake, it gets a quarter of the room left
+	// and up have
+	if room[types.Right] == 0 || room[types.Right]*2 > room[types.Left] || room[types.Right]*2 > room[types.Up] {
+		t.Errorf("room %v, want right cut short", room)
+	}
+
+	// The same snake shorter than us is no threat
+	state.Board.Snakes[1] = snake("them", xy(5, 3), xy(6, 3))
+	if room := Room(state); room[types.Right]*2 < room[types.Left] {
+		t.Errorf("room %v, want right as good as left", room)
+	}
+
+	if room := Room(&types.GameState{}); len(room) != 0 {
+		t.Errorf("room %v without a head", room)
+	}
+}
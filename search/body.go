@@ -0,0 +1,98 @@
+package search
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Room left in a body for the snake to grow before the ring has to be
+// reallocated
+const bodySlack = 16
+
+// Body is a snake body kept in a ring, so that moving the head and dropping
+// the tail happen in place instead of copying every segment
+type Body struct {
+	cells []types.Coord
+	head  int
+	n     int
+}
+
+// Helper functions
+
+func (b *Body) reset(body []types.Coord) {
+	if need := len(body) + bodySlack; cap(b.cells) < need {
+		b.cells = make([]types.Coord, need)
+	}
+	b.cells = b.cells[:cap(b.cells)]
+	b.head = 0
+	b.n = copy(b.cells, body)
+}
+
+// grow makes room for one more segment, keeping the segments in order
+func (b *Body) grow() {
+	cells := make([]types.Coord, 2*len(b.cells)+bodySlack)
+	for i := 0; i < b.n; i++ {
+		cells[i] = b.At(i)
+	}
+	b.cells = cells
+	b.head = 0
+}
+
+func (b *Body) index(i int) int {
+	return (b.head + i) % len(b.cells)
+}
+
+func (b *Body) pushHead(coord types.Coord) {
+	if b.n == len(b.cells) {
+		b.grow()
+	}
+	b.head = (b.head + len(b.cells) - 1) % len(b.cells)
+	b.cells[b.head] = coord
+	b.n++
+}
+
+func (b *Body) popHead() types.Coord {
+	coord := b.cells[b.head]
+	b.head = (b.head + 1) % len(b.cells)
+	b.n--
+	return coord
+}
+
+func (b *Body) pushTail(coord types.Coord) {
+	if b.n == len(b.cells) {
+		b.grow()
+	}
+	b.cells[b.index(b.n)] = coord
+	b.n++
+}
+
+func (b *Body) popTail() types.Coord {
+	b.n--
+	return b.cells[b.index(b.n)]
+}
+
+// Main logic
+
+func (b *Body) Len() int {
+	return b.n
+}
+
+// At returns segment i of the body, 0 being the head
+func (b *Body) At(i int) types.Coord {
+	return b.cells[b.index(i)]
+}
+
+func (b *Body) Head() types.Coord {
+	return b.At(0)
+}
+
+func (b *Body) Tail() types.Coord {
+	return b.At(b.n - 1)
+}
+
+// AppendTo appends the segments to dst, head first
+func (b *Body) AppendTo(dst []types.Coord) []types.Coord {
+	for i := 0; i < b.n; i++ {
+		dst = append(dst, b.At(i))
+	}
+	return dst
+}
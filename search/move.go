@@ -0,0 +1,264 @@
+package search
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// What Unmake needs to take back the move of one snake
+type undo struct {
+	head   types.Coord
+	tail   types.Coord
+	health int
+	grew   int // Copies of the tail added
+	died   bool
+}
+
+type ply struct {
+	eaten int    // Length of eaten before the ply
+	hash  uint64 // Hash before the ply
+}
+
+// Helper functions
+
+func (s *State) currentDirection(sn *Snake) types.Direction {
+	if sn.Body.Len() < 2 {
+		return types.Up
+	}
+	if move := s.Geometry.Direction(sn.Body.At(1), sn.Body.Head()); move.Valid() {
+		return move
+	}
+	return types.Up
+}
+
+func (s *State) grow(i int, u *undo) {
+	sn := &s.Snakes[i]
+	tail := sn.Body.Tail()
+	sn.Body.pushTail(tail)
+	s.addSegment(i, tail)
+	u.grew++
+}
+
+func (s *State) eat(cell int) {
+	if s.food[cell] {
+		s.food[cell] = false
+		s.foodCount--
+		s.eaten = append(s.eaten, cell)
+		s.hash -= key(kindFood, 0, uint64(cell))
+	}
+}
+
+func (s *State) kill(i int, u *undo, cause rules.EliminationCause) {
+	sn := &s.Snakes[i]
+	sn.Eliminated = cause
+	u.died = true
+	for k := 0; k < sn.Body.Len(); k++ {
+		s.removeSegment(i, sn.Body.At(k))
+	}
+}
+
+// eliminate takes the snakes that died this turn off the board, in the
+// same order as the engine: starvation and walls first, then collisions
+// with the survivors, all decided before any of them is applied
+func (s *State) eliminate(undos []undo) {
+	for i := range s.Snakes {
+		sn := &s.Snakes[i]
+		switch {
+		case !sn.Alive():
+		case sn.Health <= 0:
+			s.kill(i, &undos[i], rules.CauseOutOfHealth)
+		case s.cell(sn.Body.Head()) < 0:
+			s.kill(i, &undos[i], rules.CauseOutOfBounds)
+		}
+	}
+
+	s.causes = s.causes[:0]
+	for i := range s.Snakes {
+		s.causes = append(s.causes, s.collision(i))
+	}
+
+	for i, cause := range s.causes {
+		if cause != "" {
+			s.kill(i, &undos[i], cause)
+		}
+	}
+}
+
+func (s *State) collision(i int) rules.EliminationCause {
+	sn := &s.Snakes[i]
+	if !sn.Alive() {
+		return ""
+	}
+	head := sn.Body.Head()
+
+	// Every segment on the cell that is not a head is a body we ran into
+	heads := 0
+	for j := range s.Snakes {
+		if s.Snakes[j].Alive() && s.Snakes[j].Body.Head() == head {
+			heads++
+		}
+	}
+	if int(s.segmentsAt[s.cell(head)]) > heads {
+		for k := 1; k < sn.Body.Len(); k++ {
+			if sn.Body.At(k) == head {
+				return rules.CauseSelfCollision
+			}
+		}
+		return rules.CauseCollision
+	}
+
+	for j := range s.Snakes {
+		other := &s.Snakes[j]
+		if j != i && other.Alive() && other.Body.Head() == head && sn.Body.Len() <= other.Body.Len() {
+			return rules.CauseHeadToHead
+		}
+	}
+
+	return ""
+}
+
+// Main logic
+
+// Make plays a turn in place, with moves[i] the move of Snakes[i]. Snakes
+// without a valid move keep going in their current direction, like in the
+// engine. No food spawns and hazards do not change: nobody can tell where
+// they will be.
+func (s *State) Make(moves []types.Direction) {
+	s.plies = append(s.plies, ply{eaten: len(s.eaten), hash: s.hash})
+	base := len(s.undo)
+	for range s.Snakes {
+		s.undo = append(s.undo, undo{})
+	}
+	undos := s.undo[base:]
+	s.Turn++
+
+	for i := range s.Snakes {
+		sn := &s.Snakes[i]
+		if !sn.Alive() {
+			continue
+		}
+
+		move := types.NoDirection
+		if i < len(moves) {
+			move = moves[i]
+		}
+		if !move.Valid() {
+			move = s.currentDirection(sn)
+		}
+
+		u := &undos[i]
+		u.head = sn.Body.Head()
+		u.health = sn.Health
+		u.tail = sn.Body.popTail()
+		s.removeSegment(i, u.tail)
+
+		head := s.Geometry.Move(u.head, move)
+		sn.Body.pushHead(head)
+		s.addSegment(i, head)
+		sn.Health--
+	}
+
+	// Eating cancels hazard damage for the turn, stacked hazards deal it
+	// once per stack
+	for i := range s.Snakes {
+		sn := &s.Snakes[i]
+		cell := s.cell(sn.Body.Head())
+		if !sn.Alive() || cell < 0 || s.food[cell] || s.hazardsAt[cell] == 0 {
+			continue
+		}
+		sn.Health -= s.damage * int(s.hazardsAt[cell])
+		if sn.Health < 0 {
+			sn.Health = 0
+		}
+	}
+
+	// Every snake on a food eats it, only then is it gone
+	for i := range s.Snakes {
+		sn := &s.Snakes[i]
+		cell := s.cell(sn.Body.Head())
+		if sn.Alive() && cell >= 0 && s.food[cell] {
+			sn.Health = rules.MaxHealth
+			s.grow(i, &undos[i])
+		}
+	}
+	for i := range s.Snakes {
+		if undos[i].grew > 0 {
+			s.eat(s.cell(s.Snakes[i].Body.Head()))
+		}
+	}
+
+	if s.constrictor {
+		for cell := 0; s.foodCount > 0 && cell < len(s.food); cell++ {
+			s.eat(cell)
+		}
+		for i := range s.Snakes {
+			if sn := &s.Snakes[i]; sn.Alive() {
+				sn.Health = rules.MaxHealth
+				s.grow(i, &undos[i])
+			}
+		}
+	}
+
+	s.eliminate(undos)
+
+	// Segments are hashed as they move, heads and health once they are
+	// settled
+	for i := range s.Snakes {
+		sn := &s.Snakes[i]
+		u := &undos[i]
+		if !sn.Alive() && !u.died {
+			continue
+		}
+		s.removeKey(i, kindHead, s.cellKey(u.head))
+		s.removeKey(i, kindHealth, healthIndex(u.health))
+		if sn.Alive() {
+			s.addKey(i, kindHead, s.cellKey(sn.Body.Head()))
+			s.addKey(i, kindHealth, healthIndex(sn.Health))
+		}
+	}
+}
+
+// Unmake takes back the last turn played with Make
+func (s *State) Unmake() {
+	p := s.plies[len(s.plies)-1]
+	s.plies = s.plies[:len(s.plies)-1]
+	base := len(s.undo) - len(s.Snakes)
+	undos := s.undo[base:]
+	s.Turn--
+
+	for i := range s.Snakes {
+		sn := &s.Snakes[i]
+		if !undos[i].died {
+			continue
+		}
+		sn.Eliminated = ""
+		for k := 0; k < sn.Body.Len(); k++ {
+			s.occupy(sn.Body.At(k))
+		}
+	}
+
+	for _, cell := range s.eaten[p.eaten:] {
+		s.food[cell] = true
+		s.foodCount++
+	}
+	s.eaten = s.eaten[:p.eaten]
+
+	for i := range s.Snakes {
+		sn := &s.Snakes[i]
+		if !sn.Alive() {
+			continue
+		}
+
+		u := &undos[i]
+		for ; u.grew > 0; u.grew-- {
+			s.vacate(sn.Body.popTail())
+		}
+		s.vacate(sn.Body.popHead())
+		sn.Body.pushTail(u.tail)
+		s.occupy(u.tail)
+		sn.Health = u.health
+	}
+
+	s.undo = s.undo[:base]
+	s.hash = p.hash
+}
@@ -0,0 +1,325 @@
+package search
+
+import (
+	"sync"
+
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+type Snake struct {
+	ID     string
+	Name   string
+	Health int
+	Body   Body
+
+	// Slot the snake is hashed in
+	slot uint64
+
+	// Why the snake was eliminated, "" while it is still on the board
+	Eliminated rules.EliminationCause
+}
+
+func (s *Snake) Alive() bool {
+	return s.Eliminated == ""
+}
+
+// State is a game state made for searching: moves are made and unmade in
+// place, and every board question is a lookup. States come from a pool,
+// build one with From and Release it once the search is over.
+type State struct {
+	Geometry rules.Geometry
+	Turn     int
+	Snakes   []Snake
+
+	// Index in Snakes of our snake, -1 when it was not on the board
+	You int
+
+	game    types.Game
+	you     types.Battlesnake
+	hazards []types.Coord
+
+	damage      int
+	constrictor bool
+
+	// By cell, y*width+x
+	food       []bool
+	foodCount  int
+	hazardsAt  []int32
+	segmentsAt []int32
+
+	// Zobrist hash of the position
+	hash uint64
+
+	// Undo stack, one undo per snake per ply
+	undo   []undo
+	plies  []ply
+	eaten  []int
+	causes []rules.EliminationCause
+}
+
+var pool = sync.Pool{
+	New: func() interface{} {
+		return new(State)
+	},
+}
+
+// Helper functions
+
+func resetBools(buf []bool, n int) []bool {
+	if cap(buf) < n {
+		return make([]bool, n)
+	}
+	buf = buf[:n]
+	for i := range buf {
+		buf[i] = false
+	}
+	return buf
+}
+
+func resetInts(buf []int32, n int) []int32 {
+	if cap(buf) < n {
+		return make([]int32, n)
+	}
+	buf = buf[:n]
+	for i := range buf {
+		buf[i] = 0
+	}
+	return buf
+}
+
+// cell returns the index of coord in the by-cell slices, or -1 when it is
+// off the board
+func (s *State) cell(coord types.Coord) int {
+	if !s.Geometry.InBounds(coord) {
+		return -1
+	}
+	return coord.Y*s.Geometry.Width + coord.X
+}
+
+func (s *State) occupy(coord types.Coord) {
+	if cell := s.cell(coord); cell >= 0 {
+		s.segmentsAt[cell]++
+	}
+}
+
+func (s *State) vacate(coord types.Coord) {
+	if cell := s.cell(coord); cell >= 0 {
+		s.segmentsAt[cell]--
+	}
+}
+
+// cellKey is the index of coord in the keys of the hash, off-board cells
+// included
+func (s *State) cellKey(coord types.Coord) uint64 {
+	return cellIndex(s.Geometry.Width, s.Geometry.Height, coord)
+}
+
+// addKey adds the key of kind at index for snake i to the hash, twice for
+// our snake. Keys are added rather than xored so that stacked segments do
+// not cancel out, and so every key has to be: mixing the two operations
+// would make the hash depend on the order of the moves.
+func (s *State) addKey(i int, kind, index uint64) {
+	s.hash += key(kind, s.Snakes[i].slot, index)
+	if i == s.You {
+		s.hash += key(kind, s.Snakes[i].slot^youSalt, index)
+	}
+}
+
+func (s *State) removeKey(i int, kind, index uint64) {
+	s.hash -= key(kind, s.Snakes[i].slot, index)
+	if i == s.You {
+		s.hash -= key(kind, s.Snakes[i].slot^youSalt, index)
+	}
+}
+
+// addSegment puts a segment of snake i on coord
+func (s *State) addSegment(i int, coord types.Coord) {
+	if cell := s.cell(coord); cell >= 0 {
+		s.segmentsAt[cell]++
+		s.addKey(i, kindSegment, uint64(cell))
+	}
+}
+
+func (s *State) removeSegment(i int, coord types.Coord) {
+	if cell := s.cell(coord); cell >= 0 {
+		s.segmentsAt[cell]--
+		s.removeKey(i, kindSegment, uint64(cell))
+	}
+}
+
+// addSnake appends a snake to Snakes, reusing the body of the one that was
+// there the last time the state was used
+func (s *State) addSnake() *Snake {
+	if len(s.Snakes) < cap(s.Snakes) {
+		s.Snakes = s.Snakes[:len(s.Snakes)+1]
+	} else {
+		s.Snakes = append(s.Snakes, Snake{})
+	}
+	return &s.Snakes[len(s.Snakes)-1]
+}
+
+func (s *Snake) battlesnake() types.Battlesnake {
+	body := s.Body.AppendTo(make([]types.Coord, 0, s.Body.Len()))
+	return types.Battlesnake{
+		ID:     s.ID,
+		Name:   s.Name,
+		Health: s.Health,
+		Body:   body,
+		Head:   body[0],
+		Length: len(body),
+	}
+}
+
+// Main logic
+
+// From builds a search state out of state, with buffers from the pool.
+// Snakes without a body are left out.
+func From(state *types.GameState) *State {
+	s := pool.Get().(*State)
+
+	s.Geometry = rules.GeometryOf(state)
+	s.Turn = state.Turn
+	s.You = -1
+	s.game = state.Game
+	s.you = state.You
+	s.hazards = append(s.hazards[:0], state.Board.Hazards...)
+
+	s.damage = rules.HazardDamage(state)
+	s.constrictor = state.Game.Ruleset.Name == rules.Constrictor
+
+	size := 0
+	if s.Geometry.Width > 0 && s.Geometry.Height > 0 {
+		size = s.Geometry.Width * s.Geometry.Height
+	}
+	s.food = resetBools(s.food, size)
+	s.hazardsAt = resetInts(s.hazardsAt, size)
+	s.segmentsAt = resetInts(s.segmentsAt, size)
+
+	s.undo = s.undo[:0]
+	s.plies = s.plies[:0]
+	s.eaten = s.eaten[:0]
+	s.hash = 0
+
+	s.foodCount = 0
+	for _, coord := range state.Board.Food {
+		if cell := s.cell(coord); cell >= 0 && !s.food[cell] {
+			s.food[cell] = true
+			s.foodCount++
+		}
+		s.hash += key(kindFood, 0, s.cellKey(coord))
+	}
+
+	for _, coord := range state.Board.Hazards {
+		if cell := s.cell(coord); cell >= 0 {
+			s.hazardsAt[cell]++
+		}
+		s.hash += key(kindHazard, 0, s.cellKey(coord))
+	}
+
+	s.Snakes = s.Snakes[:0]
+	for _, snake := range state.Board.Snakes {
+		if len(snake.Body) == 0 {
+			continue
+		}
+
+		if snake.ID == state.You.ID {
+			s.You = len(s.Snakes)
+		}
+
+		sn := s.addSnake()
+		sn.ID = snake.ID
+		sn.Name = snake.Name
+		sn.Health = snake.Health
+		sn.Eliminated = ""
+		sn.slot = slotOf(snake.ID)
+		sn.Body.reset(snake.Body)
+
+		i := len(s.Snakes) - 1
+		for _, coord := range snake.Body {
+			s.addSegment(i, coord)
+		}
+		s.addKey(i, kindHead, s.cellKey(snake.Body[0]))
+		s.addKey(i, kindHealth, healthIndex(snake.Health))
+	}
+
+	return s
+}
+
+// Release puts s back in the pool. s must not be used afterwards.
+func (s *State) Release() {
+	pool.Put(s)
+}
+
+// GameState converts s back, at the depth it is at. Snakes that were
+// eliminated are left out, like the engine does, and food comes in board
+// order rather than the order it was given in.
+func (s *State) GameState() *types.GameState {
+	state := &types.GameState{
+		Game: s.game,
+		Turn: s.Turn,
+		Board: types.Board{
+			Width:   s.Geometry.Width,
+			Height:  s.Geometry.Height,
+			Food:    make([]types.Coord, 0, s.foodCount),
+			Hazards: append([]types.Coord(nil), s.hazards...),
+		},
+		You: s.you,
+	}
+
+	for cell, food := range s.food {
+		if food {
+			state.Board.Food = append(state.Board.Food, types.Coord{X: cell % s.Geometry.Width, Y: cell / s.Geometry.Width})
+		}
+	}
+
+	for i := range s.Snakes {
+		if !s.Snakes[i].Alive() {
+			continue
+		}
+		snake := s.Snakes[i].battlesnake()
+		state.Board.Snakes = append(state.Board.Snakes, snake)
+		if i == s.You {
+			state.You = snake
+		}
+	}
+
+	// Without our snake on the board, You is what it was before the search
+	if s.You < 0 || !s.Snakes[s.You].Alive() {
+		state.You.Body = append([]types.Coord(nil), state.You.Body...)
+	}
+
+	return state
+}
+
+// Hash is the Zobrist hash of the position, the one Hash returns for its
+// GameState. It is kept up to date by Make and Unmake.
+func (s *State) Hash() uint64 {
+	return s.hash
+}
+
+// Depth is the number of moves made since From
+func (s *State) Depth() int {
+	return len(s.plies)
+}
+
+// Occupied reports whether a snake still on the board has a segment on
+// coord
+func (s *State) Occupied(coord types.Coord) bool {
+	cell := s.cell(coord)
+	return cell >= 0 && s.segmentsAt[cell] > 0
+}
+
+func (s *State) IsFood(coord types.Coord) bool {
+	cell := s.cell(coord)
+	return cell >= 0 && s.food[cell]
+}
+
+// Hazards returns the number of hazards stacked on coord
+func (s *State) Hazards(coord types.Coord) int {
+	cell := s.cell(coord)
+	if cell < 0 {
+		return 0
+	}
+	return int(s.hazardsAt[cell])
+}
@@ -0,0 +1,266 @@
+package search
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Helper functions
+
+// randomGame places snakes on a board by walking their bodies out from a
+// random head, with food and hazards on random free cells
+func randomGame(r *rand.Rand, ruleset string, size, snakes int) *types.GameState {
+	state := &types.GameState{
+		Game: types.Game{
+			ID:      "test",
+			Ruleset: types.Ruleset{Name: ruleset},
+		},
+		Board: types.Board{Width: size, Height: size},
+	}
+	geo := rules.GeometryOf(state)
+
+	taken := make(map[types.Coord]bool)
+	free := func() (types.Coord, bool) {
+		for tries := 0; tries < 100; tries++ {
+			coord := types.Coord{X: r.Intn(size), Y: r.Intn(size)}
+			if !taken[coord] {
+				return coord, true
+			}
+		}
+		return types.Coord{}, false
+	}
+
+	for i := 0; i < snakes; i++ {
+		head, ok := free()
+		if !ok {
+			break
+		}
+		body := []types.Coord{head}
+		taken[head] = true
+		for length := 2 + r.Intn(5); len(body) < length; {
+			last := body[len(body)-1]
+			next := geo.Move(last, types.Directions()[r.Intn(4)])
+			if !geo.InBounds(next) || taken[next] {
+				// Stacked, like a snake that just ate
+				next = last
+			}
+			body = append(body, next)
+			taken[next] = true
+		}
+
+		id := fmt.Sprintf("snake-%d", i+1)
+		state.Board.Snakes = append(state.Board.Snakes, types.Battlesnake{
+			ID:     id,
+			Name:   id,
+			Health: 1 + r.Intn(rules.MaxHealth),
+			Body:   body,
+			Head:   body[0],
+			Length: len(body),
+		})
+	}
+
+	for i := 0; i < 3; i++ {
+		if coord, ok := free(); ok && ruleset != rules.Constrictor {
+			state.Board.Food = append(state.Board.Food, coord)
+			taken[coord] = true
+		}
+	}
+	if ruleset == rules.Royale {
+		for i := 0; i < size; i++ {
+			state.Board.Hazards = append(state.Board.Hazards, types.Coord{X: i, Y: 0})
+		}
+	}
+
+	state.You = state.Board.Snakes[0]
+	return state
+}
+
+func randomMoves(r *rand.Rand, s *State) []types.Direction {
+	moves := make([]types.Direction, len(s.Snakes))
+	for i := range moves {
+		moves[i] = types.Directions()[r.Intn(4)]
+	}
+	return moves
+}
+
+// sameState reports how got differs from want, the food in any order
+func sameState(got, want *types.GameState) error {
+	food := func(coords []types.Coord) []types.Coord {
+		sorted := append([]types.Coord(nil), coords...)
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].Y != sorted[j].Y {
+				return sorted[i].Y < sorted[j].Y
+			}
+			return sorted[i].X < sorted[j].X
+		})
+		return sorted
+	}
+
+	if got.Turn != want.Turn {
+		return fmt.Errorf("turn %d, want %d", got.Turn, want.Turn)
+	}
+	if !reflect.DeepEqual(food(got.Board.Food), food(want.Board.Food)) {
+		return fmt.Errorf("food %v, want %v", got.Board.Food, want.Board.Food)
+	}
+	if len(got.Board.Snakes) != len(want.Board.Snakes) {
+		return fmt.Errorf("%d snakes, want %d", len(got.Board.Snakes), len(want.Board.Snakes))
+	}
+	for i, snake := range want.Board.Snakes {
+		g := got.Board.Snakes[i]
+		if g.ID != snake.ID || g.Health != snake.Health || !reflect.DeepEqual(g.Body, snake.Body) {
+			return fmt.Errorf("snake %s health %d body %v, want %s health %d body %v", g.ID, g.Health, g.Body, snake.ID, snake.Health, snake.Body)
+		}
+	}
+	return nil
+}
+
+// Tests
+
+func TestMakeMatchesNext(t *testing.T) {
+	for _, ruleset := range []string{rules.Standard, rules.Wrapped, rules.Constrictor, rules.Royale} {
+		t.Run(ruleset, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			for game := 0; game < 200; game++ {
+				state := randomGame(r, ruleset, 7, 4)
+				s := From(state)
+
+				for ply := 0; ply < 10 && len(state.Board.Snakes) > 0; ply++ {
+					moves := randomMoves(r, s)
+					byID := make(map[string]types.Direction, len(moves))
+					for i, move := range moves {
+						byID[s.Snakes[i].ID] = move
+					}
+
+					want := rules.Next(state, byID, nil).State
+					s.Make(moves)
+
+					if err := sameState(s.GameState(), want); err != nil {
+						t.Fatalf("game %d ply %d: %s", game, ply, err)
+					}
+					if s.Hash() != Hash(want) {
+						t.Fatalf("game %d ply %d: incremental hash differs from Hash", game, ply)
+					}
+					state = want
+				}
+
+				s.Release()
+			}
+		})
+	}
+}
+
+func TestUnmakeRestores(t *testing.T) {
+	for _, ruleset := range []string{rules.Standard, rules.Wrapped, rules.Constrictor, rules.Royale} {
+		t.Run(ruleset, func(t *testing.T) {
+			r := rand.New(rand.NewSource(2))
+			for game := 0; game < 200; game++ {
+				s := From(randomGame(r, ruleset, 7, 4))
+
+				var states []*types.GameState
+				var hashes []uint64
+				for ply := 0; ply < 10; ply++ {
+					states = append(states, s.GameState())
+					hashes = append(hashes, s.Hash())
+					s.Make(randomMoves(r, s))
+				}
+
+				for ply := len(states) - 1; ply >= 0; ply-- {
+					s.Unmake()
+					if err := sameState(s.GameState(), states[ply]); err != nil {
+						t.Fatalf("game %d back to ply %d: %s", game, ply, err)
+					}
+					if s.Hash() != hashes[ply] {
+						t.Fatalf("game %d back to ply %d: hash not restored", game, ply)
+					}
+				}
+				if s.Depth() != 0 {
+					t.Fatalf("game %d: depth %d after unmaking every move", game, s.Depth())
+				}
+
+				s.Release()
+			}
+		})
+	}
+}
+
+// Benchmarks
+
+const benchDepth = 16
+
+// benchGame is a game of four snakes on a standard board, and benchDepth
+// turns of moves that keep them alive as long as they can, so both ways of
+// searching replay the same line
+func benchGame() (*types.GameState, [][]types.Direction) {
+	r := rand.New(rand.NewSource(1))
+	state := randomGame(r, rules.Standard, 11, 4)
+	s := From(state)
+	defer s.Release()
+
+	turns := make([][]types.Direction, benchDepth)
+	for t := range turns {
+		moves := make([]types.Direction, len(s.Snakes))
+		for i := range s.Snakes {
+			head := s.Snakes[i].Body.Head()
+			directions := types.Directions()
+			offset := r.Intn(len(directions))
+			for k := range directions {
+				move := directions[(offset+k)%len(directions)]
+				next := s.Geometry.Move(head, move)
+				if s.Geometry.InBounds(next) && !s.Occupied(next) {
+					moves[i] = move
+					break
+				}
+			}
+		}
+		turns[t] = moves
+		s.Make(moves)
+	}
+
+	return state, turns
+}
+
+// BenchmarkNext searches benchDepth plies by copying the state on every ply
+func BenchmarkNext(b *testing.B) {
+	state, turns := benchGame()
+	byID := make([]map[string]types.Direction, len(turns))
+	for t, moves := range turns {
+		byID[t] = make(map[string]types.Direction, len(moves))
+		for i, move := range moves {
+			byID[t][state.Board.Snakes[i].ID] = move
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		current := state
+		for t := range turns {
+			current = rules.Next(current, byID[t], nil).State
+		}
+	}
+}
+
+// BenchmarkMakeUnmake searches benchDepth plies by making and unmaking
+// moves in place
+func BenchmarkMakeUnmake(b *testing.B) {
+	state, turns := benchGame()
+	s := From(state)
+	defer s.Release()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for t := range turns {
+			s.Make(turns[t])
+		}
+		for range turns {
+			s.Unmake()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"sync"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Entries in the tables handed out by AcquireTable
+const DefaultTableSize = 1 << 14
+
+// Entry is what a search remembers of a position
+type Entry struct {
+	Hash  uint64
+	Depth int // Plies searched below the position
+	Score int
+	Move  types.Direction // Best move found, if any
+}
+
+type slot struct {
+	Entry
+	used bool
+}
+
+// Table is a transposition table: a fixed number of entries, indexed by
+// hash. When two positions want the same slot, the one searched deeper
+// stays, it is the one that cost the most to find.
+type Table struct {
+	slots []slot
+	mask  uint64
+
+	Hits   int
+	Misses int
+}
+
+var tables = sync.Pool{
+	New: func() interface{} {
+		return NewTable(DefaultTableSize)
+	},
+}
+
+// NewTable returns a table of size entries, rounded down to a power of two
+func NewTable(size int) *Table {
+	n := 1
+	for n*2 <= size {
+		n *= 2
+	}
+	return &Table{
+		slots: make([]slot, n),
+		mask:  uint64(n - 1),
+	}
+}
+
+// AcquireTable returns an empty table from a pool. Release it once the
+// search is over.
+func AcquireTable() *Table {
+	t := tables.Get().(*Table)
+	t.Clear()
+	return t
+}
+
+// Release puts t back in the pool. t must not be used afterwards.
+func (t *Table) Release() {
+	tables.Put(t)
+}
+
+func (t *Table) Clear() {
+	for i := range t.slots {
+		t.slots[i] = slot{}
+	}
+	t.Hits, t.Misses = 0, 0
+}
+
+// Probe returns the entry stored for hash
+func (t *Table) Probe(hash uint64) (Entry, bool) {
+	s := &t.slots[hash&t.mask]
+	if !s.used || s.Hash != hash {
+		t.Misses++
+		return Entry{}, false
+	}
+	t.Hits++
+	return s.Entry, true
+}
+
+// Store keeps e unless its slot holds a search deeper than e's
+func (t *Table) Store(e Entry) {
+	s := &t.slots[e.Hash&t.mask]
+	if s.used && s.Depth > e.Depth {
+		return
+	}
+	s.Entry = e
+	s.used = true
+}
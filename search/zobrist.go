@@ -0,0 +1,203 @@
+package search
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// What a key stands for
+const (
+	kindSegment uint64 = iota + 1
+	kindHead
+	kindHealth
+	kindFood
+	kindHazard
+	kindMove
+)
+
+// Mixed into the slot of our snake to hash it a second time, as us
+const youSalt = 0x9e3779b97f4a7c15
+
+// Helper functions
+
+// key returns the Zobrist key of kind at index for slot. Keys are mixed out
+// of their coordinates instead of drawn into a table, so any board size and
+// any number of snakes can be hashed.
+func key(kind, slot, index uint64) uint64 {
+	// splitmix64
+	z := slot ^ kind*0xbf58476d1ce4e5b9 ^ (index+1)*0x94d049bb133111eb
+	z += 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// slotOf is the slot a snake is hashed in, from its ID so that it does not
+// change when snakes before it are eliminated
+func slotOf(id string) uint64 {
+	// FNV-1a, without the allocation of hash/fnv
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(id); i++ {
+		h ^= uint64(id[i])
+		h *= 1099511628211
+	}
+	return h
+}
+
+// healthIndex is the index health is hashed at. Health is hashed exactly:
+// near starvation, or in hazards, a point of health is the difference
+// between a snake that lives and one that dies.
+func healthIndex(health int) uint64 {
+	if health < 0 {
+		health = 0
+	}
+	return uint64(health)
+}
+
+func cellIndex(width, height int, coord types.Coord) uint64 {
+	if coord.X < 0 || coord.Y < 0 || coord.X >= width || coord.Y >= height {
+		return ^uint64(0)
+	}
+	return uint64(coord.Y*width + coord.X)
+}
+
+// snakeKeys returns the keys of a snake in slot: its segments on the
+// board, its head and its health
+func snakeKeys(width, height int, slot uint64, body []types.Coord, health int) uint64 {
+	var keys uint64
+	for _, coord := range body {
+		if index := cellIndex(width, height, coord); index != ^uint64(0) {
+			keys += key(kindSegment, slot, index)
+		}
+	}
+	keys += key(kindHead, slot, cellIndex(width, height, body[0]))
+	keys += key(kindHealth, slot, healthIndex(health))
+	return keys
+}
+
+// coordKeys returns the keys of kind for every coord
+func coordKeys(width, height int, kind uint64, coords []types.Coord) uint64 {
+	var keys uint64
+	for _, coord := range coords {
+		keys += key(kind, 0, cellIndex(width, height, coord))
+	}
+	return keys
+}
+
+// shared reports whether a and b are the same slice, not just equal ones
+func shared(a, b []types.Coord) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// onBoard reports whether we are among the snakes on the board of state
+func onBoard(state *types.GameState) bool {
+	if len(state.You.Body) == 0 {
+		return false
+	}
+	for _, s := range state.Board.Snakes {
+		if len(s.Body) > 0 && s.ID == state.You.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Main logic
+
+// MoveKey is mixed into a hash to tell apart the moves made from the same
+// position
+func MoveKey(move types.Direction) uint64 {
+	return key(kindMove, 0, uint64(move))
+}
+
+// Hash returns the Zobrist hash of state: the bodies, heads and health of
+// the snakes, ours a second time, the food and the hazards. It is the hash
+// State keeps up to date, for strategies searching on GameStates.
+func Hash(state *types.GameState) uint64 {
+	width, height := state.Board.Width, state.Board.Height
+
+	hash := coordKeys(width, height, kindFood, state.Board.Food)
+	hash += coordKeys(width, height, kindHazard, state.Board.Hazards)
+
+	for _, s := range state.Board.Snakes {
+		if len(s.Body) > 0 {
+			hash += snakeKeys(width, height, slotOf(s.ID), s.Body, s.Health)
+		}
+	}
+
+	// Like in State, we are only hashed as us while on the board
+	if onBoard(state) {
+		hash += snakeKeys(width, height, slotOf(state.You.ID)^youSalt, state.You.Body, state.You.Health)
+	}
+
+	return hash
+}
+
+// Rehash returns the Hash of next given hash, the Hash of prev, for
+// strategies that play out plies on GameStates of their own. next is prev a
+// ply later: the same snakes in the same order, each with its head moved a
+// cell and its body following, grown by a segment at most, or not moved at
+// all. That costs a few keys a snake where Hash costs one a segment. Food
+// and hazards are hashed again only when next does not share prev's.
+func Rehash(hash uint64, prev, next *types.GameState) uint64 {
+	width, height := next.Board.Width, next.Board.Height
+	if width != prev.Board.Width || height != prev.Board.Height ||
+		len(prev.Board.Snakes) != len(next.Board.Snakes) || onBoard(prev) != onBoard(next) {
+		return Hash(next)
+	}
+
+	if !shared(prev.Board.Food, next.Board.Food) {
+		hash -= coordKeys(width, height, kindFood, prev.Board.Food)
+		hash += coordKeys(width, height, kindFood, next.Board.Food)
+	}
+	if !shared(prev.Board.Hazards, next.Board.Hazards) {
+		hash -= coordKeys(width, height, kindHazard, prev.Board.Hazards)
+		hash += coordKeys(width, height, kindHazard, next.Board.Hazards)
+	}
+
+	segment := func(slot uint64, coord types.Coord) uint64 {
+		if index := cellIndex(width, height, coord); index != ^uint64(0) {
+			return key(kindSegment, slot, index)
+		}
+		return 0
+	}
+
+	snake := func(slot uint64, before, after types.Battlesnake) {
+		grew := len(after.Body) - len(before.Body)
+		switch {
+		case len(before.Body) == 0 && len(after.Body) == 0:
+		case len(before.Body) == 0 || len(after.Body) == 0 || grew < 0 || grew > 1:
+			if len(before.Body) > 0 {
+				hash -= snakeKeys(width, height, slot, before.Body, before.Health)
+			}
+			if len(after.Body) > 0 {
+				hash += snakeKeys(width, height, slot, after.Body, after.Health)
+			}
+		default:
+			hash -= key(kindHealth, slot, healthIndex(before.Health))
+			hash += key(kindHealth, slot, healthIndex(after.Health))
+			if grew == 0 && after.Body[0] == before.Body[0] {
+				return
+			}
+
+			hash -= key(kindHead, slot, cellIndex(width, height, before.Body[0]))
+			hash += key(kindHead, slot, cellIndex(width, height, after.Body[0]))
+			hash += segment(slot, after.Body[0])
+			if grew == 0 {
+				hash -= segment(slot, before.Body[len(before.Body)-1])
+			}
+		}
+	}
+
+	for i, before := range prev.Board.Snakes {
+		after := next.Board.Snakes[i]
+		if before.ID != after.ID {
+			return Hash(next)
+		}
+		snake(slotOf(before.ID), before, after)
+	}
+	if onBoard(prev) {
+		snake(slotOf(prev.You.ID)^youSalt, prev.You, next.You)
+	}
+
+	return hash
+}
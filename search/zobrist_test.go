@@ -0,0 +1,102 @@
+package search
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Helper functions
+
+// playOut moves a snake the way a look ahead predicts it: its head a cell
+// on, growing or not, or not at all
+func playOut(r *rand.Rand, geo rules.Geometry, snake types.Battlesnake) types.Battlesnake {
+	if r.Intn(5) == 0 {
+		snake.Health = 0
+		return snake
+	}
+
+	head := geo.Move(snake.Body[0], types.Directions()[r.Intn(4)])
+	if r.Intn(3) == 0 {
+		snake.Body = append([]types.Coord{head}, snake.Body...)
+		snake.Health = rules.MaxHealth
+	} else {
+		snake.Body = append([]types.Coord{head}, snake.Body[:len(snake.Body)-1]...)
+		snake.Health--
+	}
+	snake.Head = head
+	return snake
+}
+
+// Tests
+
+func TestRehash(t *testing.T) {
+	for _, ruleset := range []string{rules.Standard, rules.Wrapped, rules.Royale} {
+		t.Run(ruleset, func(t *testing.T) {
+			r := rand.New(rand.NewSource(3))
+			for game := 0; game < 200; game++ {
+				state := randomGame(r, ruleset, 7, 4)
+				geo := rules.GeometryOf(state)
+				hash := Hash(state)
+
+				for ply := 0; ply < 10; ply++ {
+					next := *state
+					next.Board.Snakes = append([]types.Battlesnake(nil), state.Board.Snakes...)
+					for i, snake := range next.Board.Snakes {
+						next.Board.Snakes[i] = playOut(r, geo, snake)
+					}
+
+					// Our own move is played apart from the one on the board
+					next.You = playOut(r, geo, state.You)
+
+					if r.Intn(4) == 0 {
+						next.Board.Hazards = append([]types.Coord{{X: r.Intn(7), Y: r.Intn(7)}}, state.Board.Hazards...)
+					}
+					if r.Intn(4) == 0 && len(state.Board.Food) > 0 {
+						next.Board.Food = state.Board.Food[1:]
+					}
+
+					hash = Rehash(hash, state, &next)
+					if hash != Hash(&next) {
+						t.Fatalf("game %d ply %d: rehashed %x, want %x", game, ply, hash, Hash(&next))
+					}
+					state = &next
+				}
+			}
+		})
+	}
+}
+
+func TestHashHealth(t *testing.T) {
+	game := func(health int) *types.GameState {
+		us := types.Battlesnake{ID: "us", Health: health, Body: []types.Coord{{X: 1, Y: 1}, {X: 1, Y: 0}, {X: 0, Y: 0}}}
+		them := types.Battlesnake{ID: "them", Health: 50, Body: []types.Coord{{X: 5, Y: 5}, {X: 5, Y: 6}, {X: 5, Y: 7}}}
+		return &types.GameState{
+			Game:  types.Game{Ruleset: types.Ruleset{Name: rules.Standard}},
+			Board: types.Board{Width: 11, Height: 11, Snakes: []types.Battlesnake{us, them}},
+			You:   us,
+		}
+	}
+
+	// Close to starving, a point of health decides who lives
+	for _, pair := range [][2]int{{1, 2}, {1, 9}, {5, 6}, {10, 19}, {91, 99}} {
+		a, b := game(pair[0]), game(pair[1])
+		if Hash(a) == Hash(b) {
+			t.Errorf("health %d and %d: same hash", pair[0], pair[1])
+		}
+
+		sa, sb := From(a), From(b)
+		if sa.Hash() == sb.Hash() {
+			t.Errorf("health %d and %d: same State hash", pair[0], pair[1])
+		}
+		sa.Release()
+		sb.Release()
+	}
+
+	// Dead is dead, however far below zero
+	if Hash(game(0)) != Hash(game(-3)) {
+		t.Error("health 0 and -3: different hashes")
+	}
+}
@@ -1,15 +1,10 @@
 package main
 
 import (
-	"io"
-
-	"github.com/samyfodil/tb_library_snake_001/types"
-	v1 "github.com/samyfodil/tb_library_snake_001/v1"
-	v2 "github.com/samyfodil/tb_library_snake_001/v2"
-	v3 "github.com/samyfodil/tb_library_snake_001/v3"
-	v4 "github.com/samyfodil/tb_library_snake_001/v4"
+	"log"
 
 	"github.com/taubyte/go-sdk/event"
+	http "github.com/taubyte/go-sdk/http/event"
 )
 
 const ServerID = "github.com/samyfodil/tb_library_snake_001"
@@ -26,12 +21,14 @@ func index(e event.Event) uint32 {
 	h.Headers().Set("Server", ServerID)
 	h.Headers().Set("Content-Type", "application/json")
 
-	response := info()
+	path, err := h.Path()
+	if err != nil {
+		return returnError(h, err)
+	}
 
-	data, err := response.MarshalJSON()
+	data, err := handleIndex(snakeKey(path))
 	if err != nil {
-		h.Write([]byte(err.Error()))
-		return 1
+		return returnError(h, err)
 	}
 
 	h.Write(data)
@@ -48,23 +45,22 @@ func start(e event.Event) uint32 {
 
 	h.Headers().Set("Server", ServerID)
 
-	state := types.GameState{}
-
-	data, err := io.ReadAll(h.Body())
+	path, err := h.Path()
 	if err != nil {
-		h.Write([]byte(err.Error()))
-		return 1
+		return returnError(h, err)
 	}
 
-	err = state.UnmarshalJSON(data)
+	err = handleStart(snakeKey(path), h.Body())
 	if err != nil {
-		h.Write([]byte(err.Error()))
-		return 1
+		return returnError(h, err)
 	}
 
 	return 0
 }
 
+// move answers with a move whatever happens, a body that could only be read
+// in part is decoded as far as it goes
+//
 //export move
 func move(e event.Event) uint32 {
 	h, err := e.HTTP()
@@ -74,54 +70,22 @@ func move(e event.Event) uint32 {
 
 	h.Headers().Set("Server", ServerID)
 
-	state := &types.GameState{}
-
-	data, err := io.ReadAll(h.Body())
+	// Without the path the move is for no snake in particular
+	path, err := h.Path()
 	if err != nil {
-		h.Write([]byte(err.Error()))
-		return 1
+		log.Printf("reading move path: %s", err)
 	}
 
-	err = state.UnmarshalJSON(data)
+	data, err := handleMove(snakeKey(path), h.Body())
 	if err != nil {
-		h.Write([]byte(err.Error()))
-		return 1
-	}
-
-	response := types.BattlesnakeMoveResponse{
-		Move: "down",
-	}
-
-	switch state.You.Name {
-	case "tau001":
-		response = v1.Domove(state)
-	case "tau002":
-		response = v1.Domove2(state)
-	case "tau003":
-		response = v1.Domove3(state)
-	case "tau004":
-		response = v1.Domove4(state)
-	case "tau005":
-		response = v1.Domove5(state)
-	case "tau006":
-		response = v2.Move(state)
-	case "tau007":
-		response = v3.Move(state)
-	case "tau008":
-		response = v4.Move(state)
+		log.Printf("move: %s", err)
 	}
 
 	h.Headers().Set("Content-Type", "application/json")
 
-	data, err = response.MarshalJSON()
-	if err != nil {
-		h.Write([]byte(err.Error()))
-		return 1
-	}
-
 	h.Write(data)
 
-	h.Return(200)
+	h.Return(statusOK)
 
 	return 0
 }
@@ -135,19 +99,24 @@ func end(e event.Event) uint32 {
 
 	h.Headers().Set("Server", ServerID)
 
-	state := types.GameState{}
-
-	data, err := io.ReadAll(h.Body())
+	path, err := h.Path()
 	if err != nil {
-		h.Write([]byte(err.Error()))
-		return 1
+		return returnError(h, err)
 	}
 
-	err = state.UnmarshalJSON(data)
+	err = handleEnd(snakeKey(path), h.Body())
 	if err != nil {
-		h.Write([]byte(err.Error()))
-		return 1
+		return returnError(h, err)
 	}
 
 	return 0
 }
+
+// returnError answers with err as a JSON error object. The request was
+// handled, so the function still succeeds.
+func returnError(h http.Event, err error) uint32 {
+	h.Headers().Set("Content-Type", "application/json")
+	h.Write(errorBody(err))
+	h.Return(statusOf(err))
+	return 0
+}
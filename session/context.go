@@ -0,0 +1,19 @@
+package session
+
+import (
+	"context"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the session, for strategies to
+// find with FromContext
+func NewContext(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// FromContext returns the session carried by ctx, or nil
+func FromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(contextKey{}).(*Session)
+	return s
+}
@@ -0,0 +1,136 @@
+package session
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/taubyte/go-sdk/database"
+	"github.com/taubyte/go-sdk/errno"
+)
+
+// Prefix of the keys sessions are written under in a Taubyte database
+const databasePrefix = "sessions/"
+
+// DatabaseStore keeps sessions as JSON in a Taubyte database, so they
+// outlive the instance that served /start. The database is opened on first
+// use.
+type DatabaseStore struct {
+	name string
+
+	lock sync.Mutex
+	db   *database.Database
+}
+
+func NewDatabaseStore(name string) *DatabaseStore {
+	return &DatabaseStore{name: name}
+}
+
+func (d *DatabaseStore) open() (database.Database, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.db == nil {
+		db, err := database.New(d.name)
+		if err != nil {
+			return 0, err
+		}
+		d.db = &db
+	}
+
+	return *d.db, nil
+}
+
+// missing turns the error the database gives for a key it does not have
+// into ErrNotFound. The SDK only gives the reason as text, so when it is not
+// the one for a missing key, the key is looked for to tell.
+func (d *DatabaseStore) missing(db database.Database, key string, err error) error {
+	if strings.Contains(err.Error(), errno.ErrorDatabaseKeyNotFound.String()) {
+		return ErrNotFound
+	}
+
+	keys, listErr := db.List(databasePrefix + key)
+	if listErr != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k == databasePrefix+key {
+			return err
+		}
+	}
+	return ErrNotFound
+}
+
+func (d *DatabaseStore) Get(key string) (*Session, error) {
+	db, err := d.open()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := db.Get(databasePrefix + key)
+	if err != nil {
+		return nil, d.missing(db, key, err)
+	}
+	if len(data) == 0 {
+		return nil, ErrNotFound
+	}
+
+	s := &Session{}
+	err = json.Unmarshal(data, s)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (d *DatabaseStore) Put(s *Session) error {
+	db, err := d.open()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return db.Put(databasePrefix+s.Key(), data)
+}
+
+func (d *DatabaseStore) Delete(key string) error {
+	db, err := d.open()
+	if err != nil {
+		return err
+	}
+
+	return db.Delete(databasePrefix + key)
+}
+
+func (d *DatabaseStore) Keys() ([]string, error) {
+	db, err := d.open()
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := db.List(databasePrefix)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, databasePrefix)
+	}
+	return keys, nil
+}
+
+// Close closes the database if it was opened
+func (d *DatabaseStore) Close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.db == nil {
+		return nil
+	}
+
+	err := d.db.Close()
+	d.db = nil
+	return err
+}
@@ -0,0 +1,222 @@
+package session
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Sessions not updated for this long are dropped. Games time out long
+// before, so a session this old belongs to a game whose /end never came.
+const DefaultTTL = 10 * time.Minute
+
+// How often Start looks for expired sessions by default. Every session has
+// to be read to find them, too much to do on every game.
+const DefaultExpireEvery = time.Minute
+
+// Manager runs sessions through a game, from /start to /end
+type Manager struct {
+	Store Store
+	TTL   time.Duration
+
+	// Least time between two looks for expired sessions, 0 to look on every
+	// Start
+	ExpireEvery time.Duration
+
+	// Clock, time.Now when nil
+	Now func() time.Time
+
+	lock    sync.Mutex
+	expired time.Time // Last look for expired sessions
+}
+
+func NewManager(store Store, ttl time.Duration) *Manager {
+	return &Manager{
+		Store:       store,
+		TTL:         ttl,
+		ExpireEvery: DefaultExpireEvery,
+	}
+}
+
+func (m *Manager) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+// Start creates the session of our snake in a new game, replacing any
+// previous one, and drops expired sessions when it is time to look for them.
+// The session keeps a copy of state, which may come from a pool.
+func (m *Manager) Start(state *types.GameState, strategy string) (*Session, error) {
+	now := m.now()
+
+	s := &Session{
+		GameID:   state.Game.ID,
+		SnakeID:  state.You.ID,
+		Strategy: strategy,
+		Started:  now,
+		Updated:  now,
+		Turn:     state.Turn,
+		Previous: []types.GameState{*rules.Clone(state)},
+	}
+
+	err := m.Store.Put(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions of other games failing to expire are no reason to turn this
+	// one down
+	if m.expireDue(now) {
+		if _, err := m.Expire(); err != nil {
+			log.Printf("expiring sessions: %s", err)
+		}
+	}
+	return s, nil
+}
+
+// Load returns the session of our snake, or ErrNotFound when it does not
+// exist or has expired
+func (m *Manager) Load(state *types.GameState) (*Session, error) {
+	key := Key(state.Game.ID, state.You.ID)
+
+	s, err := m.Store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.expired(m.now(), m.TTL) {
+		m.Store.Delete(key)
+		return nil, ErrNotFound
+	}
+
+	return s, nil
+}
+
+// Move loads the session for a turn and records the moves the other snakes
+// made since the last state, along with the latency of our last move. A
+// session is created when /start was missed.
+func (m *Manager) Move(state *types.GameState, strategy string) (*Session, error) {
+	s, err := m.Load(state)
+	if errors.Is(err, ErrNotFound) {
+		s, err = m.Start(state, strategy)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if last := s.Last(); last != nil && last.Turn < state.Turn {
+		recordOpponentMoves(s, last, state)
+	}
+	s.recordLatency(state)
+
+	s.Turn = state.Turn
+	s.Updated = m.now()
+
+	return s, nil
+}
+
+// Save stores the session along with a copy of the state of the turn it was
+// used for
+func (m *Manager) Save(s *Session, state *types.GameState) error {
+	if last := s.Last(); last == nil || last.Turn < state.Turn {
+		s.Previous = append(s.Previous, *rules.Clone(state))
+		if len(s.Previous) > History {
+			s.Previous = append(s.Previous[:0], s.Previous[len(s.Previous)-History:]...)
+		}
+	}
+
+	return m.Store.Put(s)
+}
+
+// End finalizes the session of our snake and evicts it from the store. The
+// finished session is returned, or nil when there was none.
+func (m *Manager) End(state *types.GameState) (*Session, error) {
+	s, err := m.Load(state)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if last := s.Last(); last != nil && last.Turn < state.Turn {
+		recordOpponentMoves(s, last, state)
+	}
+	s.Turn = state.Turn
+	s.Updated = m.now()
+	s.Finished = true
+
+	return s, m.Store.Delete(s.Key())
+}
+
+// Expire drops every session not updated within the TTL and returns how many
+// were dropped
+func (m *Manager) Expire() (int, error) {
+	if m.TTL <= 0 {
+		return 0, nil
+	}
+
+	keys, err := m.Store.Keys()
+	if err != nil {
+		return 0, err
+	}
+
+	now := m.now()
+	dropped := 0
+	for _, key := range keys {
+		s, err := m.Store.Get(key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return dropped, err
+		}
+
+		if s.expired(now, m.TTL) {
+			err = m.Store.Delete(key)
+			if err != nil {
+				return dropped, err
+			}
+			dropped++
+		}
+	}
+
+	return dropped, nil
+}
+
+// Helper functions
+
+// expireDue reports whether it is time to look for expired sessions again,
+// and if so counts now as the last look
+func (m *Manager) expireDue(now time.Time) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if !m.expired.IsZero() && now.Sub(m.expired) < m.ExpireEvery {
+		return false
+	}
+	m.expired = now
+	return true
+}
+
+// recordOpponentMoves appends the move every other snake made between two
+// states, those that cannot be told are left out
+func recordOpponentMoves(s *Session, last, state *types.GameState) {
+	diff := rules.Compare(last, state)
+
+	for _, change := range diff.Snakes {
+		if change.ID == s.SnakeID || !change.Move.Valid() {
+			continue
+		}
+		if s.OpponentMoves == nil {
+			s.OpponentMoves = make(map[string][]types.Direction)
+		}
+		s.OpponentMoves[change.ID] = append(s.OpponentMoves[change.ID], change.Move)
+	}
+}
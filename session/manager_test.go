@@ -0,0 +1,189 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Helper functions
+
+// clock is a time tests move forward by hand
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestManager(store Store) (*Manager, *clock) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := NewManager(store, DefaultTTL)
+	m.Now = c.Now
+	return m, c
+}
+
+func testState(game string, turn int) *types.GameState {
+	us := types.Battlesnake{ID: "us", Health: 100, Body: []types.Coord{{X: 1, Y: turn}}}
+	them := types.Battlesnake{ID: "them", Health: 100, Body: []types.Coord{{X: 5, Y: turn}}}
+	return &types.GameState{
+		Game:  types.Game{ID: game},
+		Turn:  turn,
+		Board: types.Board{Width: 11, Height: 11, Snakes: []types.Battlesnake{us, them}},
+		You:   us,
+	}
+}
+
+// failingKeys is a store that cannot list its sessions
+type failingKeys struct {
+	*MemoryStore
+}
+
+func (failingKeys) Keys() ([]string, error) {
+	return nil, errors.New("listing failed")
+}
+
+// Tests
+
+func TestStartExpires(t *testing.T) {
+	store := NewMemoryStore()
+	m, c := newTestManager(store)
+
+	if _, err := m.Start(testState("old", 0), "v1"); err != nil {
+		t.Fatal(err)
+	}
+	c.now = c.now.Add(DefaultTTL + time.Second)
+	if _, err := m.Start(testState("new", 0), "v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ := store.Keys()
+	if len(keys) != 1 || keys[0] != Key("new", "us") {
+		t.Errorf("sessions %v, want only the new game's", keys)
+	}
+}
+
+func TestStartExpiresEvery(t *testing.T) {
+	store := NewMemoryStore()
+	m, c := newTestManager(store)
+	m.ExpireEvery = time.Hour
+
+	if _, err := m.Start(testState("old", 0), "v1"); err != nil {
+		t.Fatal(err)
+	}
+	c.now = c.now.Add(DefaultTTL + time.Second)
+	if _, err := m.Start(testState("new", 0), "v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Too early for another look, the old session stays in the store...
+	keys, _ := store.Keys()
+	if len(keys) != 2 {
+		t.Errorf("sessions %v, want both games'", keys)
+	}
+
+	// ...but is not handed out anymore
+	if _, err := m.Load(testState("old", 1)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("loading an expired session: got %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(Key("old", "us")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired session still stored after Load: %v", err)
+	}
+}
+
+func TestStartIgnoresExpireErrors(t *testing.T) {
+	store := failingKeys{NewMemoryStore()}
+	m, _ := newTestManager(store)
+
+	s, err := m.Start(testState("game", 0), "v1")
+	if err != nil || s == nil {
+		t.Fatalf("got %v, %v, want the session", s, err)
+	}
+	if _, err := store.Get(s.Key()); err != nil {
+		t.Errorf("session not stored: %v", err)
+	}
+
+	// Neither when /start was missed
+	s, err = m.Move(testState("missed", 3), "v1")
+	if err != nil || s == nil {
+		t.Fatalf("move: got %v, %v, want the session", s, err)
+	}
+}
+
+func TestMoveStartsMissingSession(t *testing.T) {
+	m, _ := newTestManager(NewMemoryStore())
+
+	s, err := m.Move(testState("game", 5), "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GameID != "game" || s.SnakeID != "us" || s.Strategy != "v2" || s.Turn != 5 {
+		t.Errorf("got %+v", s)
+	}
+	if len(s.Previous) != 1 || s.Previous[0].Turn != 5 {
+		t.Errorf("history %d states, want the state it started from", len(s.Previous))
+	}
+
+	if err := m.Save(s, testState("game", 5)); err != nil {
+		t.Fatal(err)
+	}
+	s, err = m.Move(testState("game", 6), "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moves := s.OpponentMoves["them"]; len(moves) != 1 || moves[0] != types.Up {
+		t.Errorf("opponent moves %v, want [up]", moves)
+	}
+}
+
+func TestSaveHistory(t *testing.T) {
+	m, _ := newTestManager(NewMemoryStore())
+
+	s, err := m.Start(testState("game", 0), "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last := History + 3
+	for turn := 1; turn <= last; turn++ {
+		if err := m.Save(s, testState("game", turn)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A state not newer than the last one is not kept
+	if err := m.Save(s, testState("game", last-1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Previous) != History {
+		t.Fatalf("%d states kept, want %d", len(s.Previous), History)
+	}
+	for i, state := range s.Previous {
+		if want := last - History + 1 + i; state.Turn != want {
+			t.Errorf("state %d of turn %d, want %d", i, state.Turn, want)
+		}
+	}
+}
+
+func TestSaveCopies(t *testing.T) {
+	m, _ := newTestManager(NewMemoryStore())
+
+	s, err := m.Start(testState("game", 0), "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := testState("game", 1)
+	if err := m.Save(s, state); err != nil {
+		t.Fatal(err)
+	}
+	state.Board.Snakes[0].Body[0] = types.Coord{X: 9, Y: 9}
+
+	if s.Last().Board.Snakes[0].Body[0] == state.Board.Snakes[0].Body[0] {
+		t.Error("the session shares its state with the caller")
+	}
+}
@@ -0,0 +1,80 @@
+package session
+
+import (
+	"time"
+
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Number of previous states kept in a session
+var History = 8
+
+// Session is what we remember about one of our snakes in one game, from
+// /start to /end
+type Session struct {
+	GameID   string    `json:"gameId"`
+	SnakeID  string    `json:"snakeId"`
+	Strategy string    `json:"strategy,omitempty"`
+	Started  time.Time `json:"started"`
+	Updated  time.Time `json:"updated"`
+	Turn     int       `json:"turn"`
+	Finished bool      `json:"finished,omitempty"`
+
+	// Last states seen, oldest first, at most History of them
+	Previous []types.GameState `json:"previous,omitempty"`
+
+	// Moves every other snake made, by snake ID, in turn order
+	OpponentMoves map[string][]types.Direction `json:"opponentMoves,omitempty"`
+
+	// How long our last moves took, oldest first, at most History of them
+	Timings []Timing `json:"timings,omitempty"`
+
+	// Data strategies keep between turns, by key
+	Cache map[string][]byte `json:"cache,omitempty"`
+}
+
+// Key identifies a session, one per game and snake
+func Key(gameID, snakeID string) string {
+	return gameID + "/" + snakeID
+}
+
+func (s *Session) Key() string {
+	return Key(s.GameID, s.SnakeID)
+}
+
+// Last returns the most recent state before the current turn, or nil
+func (s *Session) Last() *types.GameState {
+	if len(s.Previous) == 0 {
+		return nil
+	}
+	return &s.Previous[len(s.Previous)-1]
+}
+
+// Changes returns what happened between the last state seen and state, or
+// false when there is no earlier state to compare with
+func (s *Session) Changes(state *types.GameState) (rules.Diff, bool) {
+	last := s.Last()
+	if last == nil || last.Turn >= state.Turn {
+		return rules.Diff{}, false
+	}
+	return rules.Compare(last, state), true
+}
+
+// Remember keeps data under key for the next turns
+func (s *Session) Remember(key string, data []byte) {
+	if s.Cache == nil {
+		s.Cache = make(map[string][]byte)
+	}
+	s.Cache[key] = data
+}
+
+// Recall returns the data kept under key
+func (s *Session) Recall(key string) ([]byte, bool) {
+	data, ok := s.Cache[key]
+	return data, ok
+}
+
+func (s *Session) expired(now time.Time, ttl time.Duration) bool {
+	return ttl > 0 && now.Sub(s.Updated) > ttl
+}
@@ -0,0 +1,69 @@
+package session
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrNotFound is returned by a Store for a key it has no session for
+var ErrNotFound = errors.New("session not found")
+
+// Store keeps sessions by key
+type Store interface {
+	Get(key string) (*Session, error)
+	Put(s *Session) error
+	Delete(key string) error
+	Keys() ([]string, error)
+}
+
+// MemoryStore keeps sessions in the memory of the process
+type MemoryStore struct {
+	lock     sync.Mutex
+	sessions map[string]*Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]*Session),
+	}
+}
+
+func (m *MemoryStore) Get(key string) (*Session, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	s, ok := m.sessions[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s, nil
+}
+
+func (m *MemoryStore) Put(s *Session) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.sessions[s.Key()] = s
+	return nil
+}
+
+func (m *MemoryStore) Delete(key string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.sessions, key)
+	return nil
+}
+
+func (m *MemoryStore) Keys() ([]string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	keys := make([]string, 0, len(m.sessions))
+	for key := range m.sessions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
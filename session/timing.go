@@ -0,0 +1,76 @@
+package session
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Network margin assumed until the engine has reported a latency
+var DefaultNetworkMargin = 100 * time.Millisecond
+
+// Added on top of the worst network time seen, for jitter
+var NetworkSlack = 20 * time.Millisecond
+
+// Timing is how long one of our moves took
+type Timing struct {
+	Turn int `json:"turn"`
+
+	// Time we spent on the move, from request to response
+	Compute time.Duration `json:"compute"`
+
+	// What is left of the latency the engine saw once our time is taken out,
+	// only known when Measured
+	Network  time.Duration `json:"network,omitempty"`
+	Measured bool          `json:"measured,omitempty"`
+}
+
+// RecordCompute keeps how long we took to answer turn
+func (s *Session) RecordCompute(turn int, compute time.Duration) {
+	s.Timings = append(s.Timings, Timing{Turn: turn, Compute: compute})
+	if len(s.Timings) > History {
+		s.Timings = append(s.Timings[:0], s.Timings[len(s.Timings)-History:]...)
+	}
+}
+
+// NetworkMargin estimates how much of the engine's timeout the network
+// takes, from the worst latency the engine reported so far in the game.
+// Moves it has not reported a latency for say nothing of the network.
+func (s *Session) NetworkMargin() time.Duration {
+	worst := time.Duration(-1)
+	for _, t := range s.Timings {
+		if t.Measured && t.Network > worst {
+			worst = t.Network
+		}
+	}
+
+	if worst < 0 {
+		return DefaultNetworkMargin
+	}
+	return worst + NetworkSlack
+}
+
+// recordLatency matches the latency the engine reports for our last move
+// with the time we took on it
+func (s *Session) recordLatency(state *types.GameState) {
+	ms, err := strconv.Atoi(state.You.Latency)
+	if err != nil || ms <= 0 {
+		return
+	}
+	latency := time.Duration(ms) * time.Millisecond
+
+	for i := len(s.Timings) - 1; i >= 0; i-- {
+		if s.Timings[i].Turn != state.Turn-1 {
+			continue
+		}
+
+		network := latency - s.Timings[i].Compute
+		if network < 0 {
+			network = 0
+		}
+		s.Timings[i].Network = network
+		s.Timings[i].Measured = true
+		return
+	}
+}
@@ -0,0 +1,64 @@
+package shout
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Longest shout the engine keeps, in bytes
+const MaxLength = 256
+
+// Limit cuts s down to MaxLength without splitting a character
+func Limit(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+
+	cut := MaxLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// Format is fmt.Sprintf for shouts, the result fits the engine's limit
+func Format(format string, args ...interface{}) string {
+	return Limit(fmt.Sprintf(format, args...))
+}
+
+// Intents shared by strategies, so the same plan reads the same way in the
+// game viewer whatever strategy is playing
+
+func Food(food types.Coord) string {
+	return Format("hunting food at (%d,%d)", food.X, food.Y)
+}
+
+func Trapped(room int) string {
+	return Format("escaping trap, %d cells left", room)
+}
+
+func Kill(snake types.Battlesnake) string {
+	return Format("going for the kill on %s", snake.Name)
+}
+
+func Room(room int) string {
+	return Format("claiming %d cells", room)
+}
+
+func LookedAhead(steps int) string {
+	return Format("looked %d moves ahead", steps)
+}
+
+func Cycle() string {
+	return "following the cycle"
+}
+
+func Tail() string {
+	return "chasing my tail"
+}
+
+func Fallback(reason string) string {
+	return Format("playing it safe, %s", reason)
+}
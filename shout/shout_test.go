@@ -0,0 +1,46 @@
+package shout
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestLimit(t *testing.T) {
+	snake := "🐍"  // 4 bytes
+	accent := "é" // 2 bytes
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "empty", in: "", want: ""},
+		{name: "short", in: "hello", want: "hello"},
+		{name: "ascii at the limit", in: strings.Repeat("a", MaxLength), want: strings.Repeat("a", MaxLength)},
+		{name: "ascii a byte over", in: strings.Repeat("a", MaxLength+1), want: strings.Repeat("a", MaxLength)},
+		{name: "runes at the limit", in: strings.Repeat(snake, MaxLength/4), want: strings.Repeat(snake, MaxLength/4)},
+		{name: "runes a byte over", in: "a" + strings.Repeat(snake, MaxLength/4), want: "a" + strings.Repeat(snake, MaxLength/4-1)},
+		{name: "two byte runes a byte over", in: "a" + strings.Repeat(accent, MaxLength/2), want: "a" + strings.Repeat(accent, MaxLength/2-1)},
+		{name: "rune ending on the limit", in: strings.Repeat("a", MaxLength-4) + snake + "b", want: strings.Repeat("a", MaxLength-4) + snake},
+		{name: "rune across the limit", in: strings.Repeat("a", MaxLength-2) + snake, want: strings.Repeat("a", MaxLength-2)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Limit(test.in)
+			if got != test.want {
+				t.Errorf("got %d bytes %q, want %d bytes %q", len(got), got, len(test.want), test.want)
+			}
+			if len(got) > MaxLength || !utf8.ValidString(got) {
+				t.Errorf("got %d bytes, valid %t", len(got), utf8.ValidString(got))
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if got := Format("%s", strings.Repeat("🐍", 100)); len(got) != MaxLength {
+		t.Errorf("got %d bytes, want %d", len(got), MaxLength)
+	}
+}
@@ -0,0 +1,38 @@
+package strategy
+
+import (
+	"context"
+)
+
+// Params tune a strategy for one snake, by name. Strategies read them with
+// Int and Float, falling back to their own defaults.
+type Params map[string]float64
+
+func (p Params) Float(name string, fallback float64) float64 {
+	if value, ok := p[name]; ok {
+		return value
+	}
+	return fallback
+}
+
+func (p Params) Int(name string, fallback int) int {
+	if value, ok := p[name]; ok {
+		return int(value)
+	}
+	return fallback
+}
+
+type paramsKey struct{}
+
+// WithParams returns a copy of ctx carrying params for the strategy it is
+// passed to
+func WithParams(ctx context.Context, params Params) context.Context {
+	return context.WithValue(ctx, paramsKey{}, params)
+}
+
+// ParamsFrom returns the params carried by ctx. A nil Params is fine to read
+// from, every value is the fallback.
+func ParamsFrom(ctx context.Context) Params {
+	params, _ := ctx.Value(paramsKey{}).(Params)
+	return params
+}
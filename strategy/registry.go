@@ -0,0 +1,108 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+var (
+	lock        sync.RWMutex
+	strategies  = make(map[string]Strategy)
+	aliases     = make(map[string]string)
+	defaultName string
+)
+
+// Register makes a strategy available under its name and any number of
+// aliases, typically the names of the snakes that run it. It is meant to be
+// called from init and panics when a name is already taken.
+func Register(s Strategy, alias ...string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	name := s.Name()
+	if _, taken := strategies[name]; taken {
+		panic(fmt.Sprintf("strategy: %q registered twice", name))
+	}
+	strategies[name] = s
+
+	for _, a := range alias {
+		if _, taken := aliases[a]; taken {
+			panic(fmt.Sprintf("strategy: alias %q registered twice", a))
+		}
+		aliases[a] = name
+	}
+}
+
+// Lookup finds a strategy by name or alias
+func Lookup(key string) (Strategy, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return lookup(key)
+}
+
+func lookup(key string) (Strategy, bool) {
+	if s, ok := strategies[key]; ok {
+		return s, true
+	}
+	if name, ok := aliases[key]; ok {
+		return strategies[name], true
+	}
+	return nil, false
+}
+
+// Names returns the name of every registered strategy, sorted
+func Names() []string {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetDefault picks the strategy used for snakes nothing else matches
+func SetDefault(key string) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	s, ok := lookup(key)
+	if !ok {
+		return fmt.Errorf("strategy: unknown strategy %q", key)
+	}
+	defaultName = s.Name()
+	return nil
+}
+
+// Default returns the default strategy, or nil if none was set
+func Default() Strategy {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return strategies[defaultName]
+}
+
+// For finds the strategy for our snake in state, by its name first, then by
+// its ID, falling back to the default strategy. It returns nil when nothing
+// matches and there is no default.
+func For(state *types.GameState) Strategy {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	for _, key := range []string{state.You.Name, state.You.ID} {
+		if key == "" {
+			continue
+		}
+		if s, ok := lookup(key); ok {
+			return s
+		}
+	}
+
+	return strategies[defaultName]
+}
@@ -0,0 +1,84 @@
+package strategy
+
+import (
+	"context"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+// Strategy is a move algorithm that can be picked for a snake. The context
+// carries the deadline of the turn, strategies that search should stop by
+// then with the best move found so far.
+//
+// The state a strategy is given comes from a pool and is reused once the
+// call returns. Neither it nor anything in it may be kept past the call,
+// Clone what has to last.
+type Strategy interface {
+	Name() string
+	Move(ctx context.Context, state *types.GameState) types.BattlesnakeMoveResponse
+}
+
+// Starter is implemented by strategies that want to know when a game
+// starts. Like with Move, state must not be kept past the call.
+type Starter interface {
+	Start(state *types.GameState)
+}
+
+// Ender is implemented by strategies that want to know when a game ends.
+// Like with Move, state must not be kept past the call.
+type Ender interface {
+	End(state *types.GameState)
+}
+
+// Informer is implemented by strategies with their own look on the board,
+// served on the snake's info route
+type Informer interface {
+	Info() types.BattlesnakeInfoResponse
+}
+
+// Func turns plain functions into a Strategy. One of MoveFunc or SearchFunc
+// is required, SearchFunc is used when both are set.
+type Func struct {
+	StrategyName string
+	MoveFunc     func(state *types.GameState) types.BattlesnakeMoveResponse
+	SearchFunc   func(ctx context.Context, state *types.GameState) types.BattlesnakeMoveResponse
+	StartFunc    func(state *types.GameState)
+	EndFunc      func(state *types.GameState)
+	About        types.BattlesnakeInfoResponse
+}
+
+func (f Func) Name() string {
+	return f.StrategyName
+}
+
+func (f Func) Move(ctx context.Context, state *types.GameState) types.BattlesnakeMoveResponse {
+	if f.SearchFunc != nil {
+		return f.SearchFunc(ctx, state)
+	}
+	return f.MoveFunc(state)
+}
+
+func (f Func) Start(state *types.GameState) {
+	if f.StartFunc != nil {
+		f.StartFunc(state)
+	}
+}
+
+func (f Func) End(state *types.GameState) {
+	if f.EndFunc != nil {
+		f.EndFunc(state)
+	}
+}
+
+// Info returns About, with the API version and the strategy name as version
+// filled in when left empty
+func (f Func) Info() types.BattlesnakeInfoResponse {
+	info := f.About
+	if info.APIVersion == "" {
+		info.APIVersion = "1"
+	}
+	if info.Version == "" {
+		info.Version = f.StrategyName
+	}
+	return info
+}
@@ -60,9 +60,14 @@ type Ruleset struct {
 }
 
 type RulesetSettings struct {
-	FoodSpawnChance     int `json:"foodSpawnChance"`
-	MinimumFood         int `json:"minimumFood"`
-	HazardDamagePerTurn int `json:"hazardDamagePerTurn"`
+	FoodSpawnChance     int            `json:"foodSpawnChance"`
+	MinimumFood         int            `json:"minimumFood"`
+	HazardDamagePerTurn int            `json:"hazardDamagePerTurn"`
+	Royale              RoyaleSettings `json:"royale"`
+}
+
+type RoyaleSettings struct {
+	ShrinkEveryNTurns int `json:"shrinkEveryNTurns"`
 }
 
 // Response Objects
@@ -74,6 +79,7 @@ type BattlesnakeInfoResponse struct {
 	Color      string `json:"color"`
 	Head       string `json:"head"`
 	Tail       string `json:"tail"`
+	Version    string `json:"version"`
 }
 
 type BattlesnakeMoveResponse struct {
@@ -81,6 +87,11 @@ type BattlesnakeMoveResponse struct {
 	Shout string `json:"shout"`
 }
 
+// Not part of the API, what our routes answer with when they fail
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
 func (original *GameState) Copy() *GameState {
 	copied := &GameState{
 		Game: original.Game,
@@ -42,6 +42,8 @@ func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types(in *jlexer.L
 			out.MinimumFood = int(in.Int())
 		case "hazardDamagePerTurn":
 			out.HazardDamagePerTurn = int(in.Int())
+		case "royale":
+			(out.Royale).UnmarshalEasyJSON(in)
 		default:
 			in.SkipRecursive()
 		}
@@ -82,6 +84,16 @@ func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types(out *jwriter
 		}
 		out.Int(int(in.HazardDamagePerTurn))
 	}
+	if true {
+		const prefix string = ",\"royale\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		(in.Royale).MarshalEasyJSON(out)
+	}
 	out.RawByte('}')
 }
 
@@ -199,7 +211,74 @@ func (v *Ruleset) UnmarshalJSON(data []byte) error {
 func (v *Ruleset) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types1(l, v)
 }
-func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types2(in *jlexer.Lexer, out *GameState) {
+func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types2(in *jlexer.Lexer, out *RoyaleSettings) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "shrinkEveryNTurns":
+			out.ShrinkEveryNTurns = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types2(out *jwriter.Writer, in RoyaleSettings) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.ShrinkEveryNTurns != 0 {
+		const prefix string = ",\"shrinkEveryNTurns\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.Int(int(in.ShrinkEveryNTurns))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RoyaleSettings) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RoyaleSettings) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RoyaleSettings) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RoyaleSettings) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types2(l, v)
+}
+func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types3(in *jlexer.Lexer, out *GameState) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -236,7 +315,7 @@ func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types2(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types2(out *jwriter.Writer, in GameState) {
+func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types3(out *jwriter.Writer, in GameState) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -282,27 +361,27 @@ func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types2(out *jwrite
 // MarshalJSON supports json.Marshaler interface
 func (v GameState) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types2(&w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v GameState) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types2(w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *GameState) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types2(&r, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *GameState) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types2(l, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types3(l, v)
 }
-func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types3(in *jlexer.Lexer, out *Game) {
+func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types4(in *jlexer.Lexer, out *Game) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -341,7 +420,7 @@ func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types3(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types3(out *jwriter.Writer, in Game) {
+func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types4(out *jwriter.Writer, in Game) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -397,27 +476,94 @@ func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types3(out *jwrite
 // MarshalJSON supports json.Marshaler interface
 func (v Game) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types3(&w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Game) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types3(w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Game) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types3(&r, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Game) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types3(l, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types4(l, v)
+}
+func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types5(in *jlexer.Lexer, out *ErrorResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types5(out *jwriter.Writer, in ErrorResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Error != "" {
+		const prefix string = ",\"error\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
 }
-func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types4(in *jlexer.Lexer, out *Customizations) {
+
+// MarshalJSON supports json.Marshaler interface
+func (v ErrorResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ErrorResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ErrorResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ErrorResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types5(l, v)
+}
+func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types6(in *jlexer.Lexer, out *Customizations) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -452,7 +598,7 @@ func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types4(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types4(out *jwriter.Writer, in Customizations) {
+func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types6(out *jwriter.Writer, in Customizations) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -488,27 +634,27 @@ func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types4(out *jwrite
 // MarshalJSON supports json.Marshaler interface
 func (v Customizations) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types4(&w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types6(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Customizations) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types4(w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types6(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Customizations) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types4(&r, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types6(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Customizations) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types4(l, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types6(l, v)
 }
-func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types5(in *jlexer.Lexer, out *Coord) {
+func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types7(in *jlexer.Lexer, out *Coord) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -541,7 +687,7 @@ func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types5(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types5(out *jwriter.Writer, in Coord) {
+func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types7(out *jwriter.Writer, in Coord) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -567,27 +713,27 @@ func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types5(out *jwrite
 // MarshalJSON supports json.Marshaler interface
 func (v Coord) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types5(&w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types7(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Coord) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types5(w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types7(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Coord) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types5(&r, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types7(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Coord) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types5(l, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types7(l, v)
 }
-func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types6(in *jlexer.Lexer, out *Board) {
+func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types8(in *jlexer.Lexer, out *Board) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -689,7 +835,7 @@ func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types6(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types6(out *jwriter.Writer, in Board) {
+func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types8(out *jwriter.Writer, in Board) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -772,27 +918,27 @@ func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types6(out *jwrite
 // MarshalJSON supports json.Marshaler interface
 func (v Board) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types6(&w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types8(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Board) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types6(w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types8(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Board) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types6(&r, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types8(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Board) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types6(l, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types8(l, v)
 }
-func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types7(in *jlexer.Lexer, out *BattlesnakeMoveResponse) {
+func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types9(in *jlexer.Lexer, out *BattlesnakeMoveResponse) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -825,7 +971,7 @@ func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types7(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types7(out *jwriter.Writer, in BattlesnakeMoveResponse) {
+func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types9(out *jwriter.Writer, in BattlesnakeMoveResponse) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -851,27 +997,27 @@ func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types7(out *jwrite
 // MarshalJSON supports json.Marshaler interface
 func (v BattlesnakeMoveResponse) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types7(&w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types9(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v BattlesnakeMoveResponse) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types7(w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types9(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *BattlesnakeMoveResponse) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types7(&r, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types9(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *BattlesnakeMoveResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types7(l, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types9(l, v)
 }
-func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types8(in *jlexer.Lexer, out *BattlesnakeInfoResponse) {
+func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types10(in *jlexer.Lexer, out *BattlesnakeInfoResponse) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -900,6 +1046,8 @@ func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types8(in *jlexer.
 			out.Head = string(in.String())
 		case "tail":
 			out.Tail = string(in.String())
+		case "version":
+			out.Version = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -910,7 +1058,7 @@ func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types8(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types8(out *jwriter.Writer, in BattlesnakeInfoResponse) {
+func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types10(out *jwriter.Writer, in BattlesnakeInfoResponse) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -960,33 +1108,43 @@ func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types8(out *jwrite
 		}
 		out.String(string(in.Tail))
 	}
+	if in.Version != "" {
+		const prefix string = ",\"version\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Version))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v BattlesnakeInfoResponse) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types8(&w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types10(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v BattlesnakeInfoResponse) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types8(w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types10(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *BattlesnakeInfoResponse) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types8(&r, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types10(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *BattlesnakeInfoResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types8(l, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types10(l, v)
 }
-func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types9(in *jlexer.Lexer, out *Battlesnake) {
+func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types11(in *jlexer.Lexer, out *Battlesnake) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -1054,7 +1212,7 @@ func easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types9(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types9(out *jwriter.Writer, in Battlesnake) {
+func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types11(out *jwriter.Writer, in Battlesnake) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -1159,23 +1317,23 @@ func easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types9(out *jwrite
 // MarshalJSON supports json.Marshaler interface
 func (v Battlesnake) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types9(&w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types11(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Battlesnake) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types9(w, v)
+	easyjsonE762775cEncodeGithubComSamyfodilTbLibrarySnake001Types11(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Battlesnake) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types9(&r, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types11(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Battlesnake) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types9(l, v)
+	easyjsonE762775cDecodeGithubComSamyfodilTbLibrarySnake001Types11(l, v)
 }
@@ -0,0 +1,141 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/mailru/easyjson/jlexer"
+)
+
+// Largest body DecodeGameState reads by default. The engine's biggest
+// boards, 25x25 with every cell taken, come well under it.
+const MaxGameStateSize = 1 << 20
+
+// TooLargeError is returned for a body over the size limit. The state is
+// decoded from the part that fit as far as it goes.
+type TooLargeError struct {
+	Limit int64
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("game state larger than %d bytes", e.Limit)
+}
+
+// TruncatedError is returned for a body that ends before the state does
+type TruncatedError struct {
+	Size int // Bytes read
+	Err  error
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("game state cut short after %d bytes: %s", e.Size, e.Err)
+}
+
+func (e *TruncatedError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	states = sync.Pool{
+		New: func() interface{} {
+			return new(GameState)
+		},
+	}
+
+	buffers = sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	}
+)
+
+// Helper functions
+
+// reset empties state, keeping the slices it grew for the next decode
+func (state *GameState) reset() {
+	*state = GameState{
+		Board: Board{
+			Food:    state.Board.Food[:0],
+			Hazards: state.Board.Hazards[:0],
+			Snakes:  state.Board.Snakes[:0],
+		},
+		You: Battlesnake{
+			Body: state.You.Body[:0],
+		},
+	}
+}
+
+// truncated reports whether the lexer stopped because the data ran out
+func truncated(err error, size int) bool {
+	var lexerError *jlexer.LexerError
+	if errors.As(err, &lexerError) {
+		return lexerError.Offset >= size
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Main logic
+
+// AcquireGameState returns an empty GameState from a pool. Release it once
+// nothing refers to it anymore, Clone what has to outlive it.
+func AcquireGameState() *GameState {
+	state := states.Get().(*GameState)
+	state.reset()
+	return state
+}
+
+// ReleaseGameState puts state back in the pool. state must not be used
+// afterwards.
+func ReleaseGameState(state *GameState) {
+	states.Put(state)
+}
+
+// DecodeGameState reads a state out of r, at most limit bytes of it, into
+// state. The lexer needs the whole body at once, so it is read into a
+// pooled buffer rather than one io.ReadAll grows anew for every request,
+// and lexed in place. Strings are copied out of the buffer, nothing in
+// state refers to it afterwards.
+//
+// A body over the limit gives a *TooLargeError and one that ends early a
+// *TruncatedError. Either way state holds what could be decoded.
+func DecodeGameState(r io.Reader, limit int64, state *GameState) error {
+	if limit <= 0 {
+		limit = MaxGameStateSize
+	}
+
+	buf := buffers.Get().(*bytes.Buffer)
+	defer func() {
+		// States are tens of kilobytes, a buffer that grew past the default
+		// limit for an odd body is not kept for good
+		if buf.Cap() <= MaxGameStateSize {
+			buf.Reset()
+			buffers.Put(buf)
+		}
+	}()
+	buf.Reset()
+
+	// One byte more than the limit tells a body at the limit from one over
+	_, readErr := buf.ReadFrom(io.LimitReader(r, limit+1))
+	data := buf.Bytes()
+	tooLarge := int64(len(data)) > limit
+	if tooLarge {
+		data = data[:limit]
+	}
+
+	lexer := jlexer.Lexer{Data: data}
+	state.UnmarshalEasyJSON(&lexer)
+	err := lexer.Error()
+
+	switch {
+	case tooLarge:
+		return &TooLargeError{Limit: limit}
+	case readErr != nil:
+		return &TruncatedError{Size: len(data), Err: readErr}
+	case err != nil && truncated(err, len(data)):
+		return &TruncatedError{Size: len(data), Err: err}
+	}
+	return err
+}
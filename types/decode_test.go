@@ -0,0 +1,249 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// Helper functions
+
+// sampleState is a game well under way on a size by size board: the snakes
+// fill most of it, lying one after the other along a path that sweeps the
+// rows back and forth, with food and hazards along the middle lines
+func sampleState(size, snakes int) *GameState {
+	state := &GameState{
+		Game: Game{
+			ID:      "sample",
+			Ruleset: Ruleset{Name: "royale", Version: "v1.2.3"},
+			Map:     "standard",
+			Timeout: 500,
+			Source:  "league",
+		},
+		Turn:  180,
+		Board: Board{Width: size, Height: size},
+	}
+
+	path := make([]Coord, 0, size*size)
+	for y := 0; y < size; y++ {
+		for k := 0; k < size; k++ {
+			x := k
+			if y%2 == 1 {
+				x = size - 1 - k
+			}
+			path = append(path, Coord{X: x, Y: y})
+		}
+	}
+
+	// Three cells in five taken, a gap between snakes
+	length := size * size * 3 / 5 / snakes
+	for i := 0; i < snakes; i++ {
+		start := i * len(path) / snakes
+		body := path[start : start+length]
+
+		id := fmt.Sprintf("gs_%d", i)
+		state.Board.Snakes = append(state.Board.Snakes, Battlesnake{
+			ID:      id,
+			Name:    "snake " + id,
+			Health:  90 - i,
+			Body:    body,
+			Latency: "123",
+			Head:    body[0],
+			Length:  len(body),
+			Shout:   "hello",
+			Customizations: Customizations{
+				Color: "#888888",
+				Head:  "default",
+				Tail:  "default",
+			},
+		})
+	}
+
+	middle := size / 2
+	for i := 0; i < size; i++ {
+		state.Board.Hazards = append(state.Board.Hazards, Coord{X: i, Y: middle}, Coord{X: middle, Y: i})
+	}
+	state.Board.Food = []Coord{{X: middle, Y: middle}, {X: 0, Y: middle}, {X: size - 1, Y: middle}}
+
+	state.You = state.Board.Snakes[0]
+	return state
+}
+
+// Boards the benchmarks decode, up to the largest the engine runs
+var benchBoards = []struct {
+	size   int
+	snakes int
+}{
+	{size: 11, snakes: 4},
+	{size: 19, snakes: 8},
+	{size: 25, snakes: 16},
+}
+
+func mustMarshal(t testing.TB, state *GameState) []byte {
+	data, err := state.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// Tests
+
+func TestDecodeGameState(t *testing.T) {
+	body := mustMarshal(t, sampleState(11, 4))
+
+	state := AcquireGameState()
+	defer ReleaseGameState(state)
+
+	if err := DecodeGameState(bytes.NewReader(body), 0, state); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustMarshal(t, state); !bytes.Equal(got, body) {
+		t.Errorf("decoded\n%s\nwant\n%s", got, body)
+	}
+}
+
+func TestDecodeGameStateAtLimit(t *testing.T) {
+	body := mustMarshal(t, sampleState(11, 4))
+
+	state := AcquireGameState()
+	defer ReleaseGameState(state)
+
+	if err := DecodeGameState(bytes.NewReader(body), int64(len(body)), state); err != nil {
+		t.Errorf("body at the limit: %s", err)
+	}
+
+	var tooLarge *TooLargeError
+	err := DecodeGameState(bytes.NewReader(body), int64(len(body)-1), state)
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != int64(len(body)-1) {
+		t.Errorf("body over the limit: got %v, want a *TooLargeError", err)
+	}
+}
+
+func TestDecodeGameStateTruncated(t *testing.T) {
+	body := mustMarshal(t, sampleState(11, 4))
+
+	tests := []struct {
+		name   string
+		reader io.Reader
+		err    error
+	}{
+		{name: "cut short", reader: bytes.NewReader(body[:len(body)/2])},
+		{name: "empty", reader: bytes.NewReader(nil)},
+		{name: "read error", reader: io.MultiReader(bytes.NewReader(body[:100]), iotest.ErrReader(io.ErrUnexpectedEOF)), err: io.ErrUnexpectedEOF},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := AcquireGameState()
+			defer ReleaseGameState(state)
+
+			var truncated *TruncatedError
+			err := DecodeGameState(test.reader, 0, state)
+			if !errors.As(err, &truncated) {
+				t.Fatalf("got %v, want a *TruncatedError", err)
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Errorf("got %v, want it to wrap %v", err, test.err)
+			}
+		})
+	}
+
+	// Broken all the way through is not truncated
+	state := AcquireGameState()
+	defer ReleaseGameState(state)
+
+	var truncated *TruncatedError
+	err := DecodeGameState(bytes.NewReader([]byte(`{"turn": "three"}`)), 0, state)
+	if err == nil || errors.As(err, &truncated) {
+		t.Errorf("broken body: got %v, want a syntax error", err)
+	}
+}
+
+func TestAcquireGameStateIsEmpty(t *testing.T) {
+	empty := mustMarshal(t, &GameState{})
+
+	state := AcquireGameState()
+	if err := DecodeGameState(bytes.NewReader(mustMarshal(t, sampleState(11, 4))), 0, state); err != nil {
+		t.Fatal(err)
+	}
+	ReleaseGameState(state)
+
+	// Whatever state the pool hands out, nothing of the last decode is left
+	for i := 0; i < 4; i++ {
+		state := AcquireGameState()
+		if got := mustMarshal(t, state); !bytes.Equal(got, empty) {
+			t.Errorf("acquired %s, want %s", got, empty)
+		}
+		defer ReleaseGameState(state)
+	}
+}
+
+func TestDecodeGameStateReused(t *testing.T) {
+	small := &GameState{
+		Game:  Game{ID: "small"},
+		Board: Board{Width: 7, Height: 7, Snakes: []Battlesnake{{ID: "a", Health: 10, Body: []Coord{{X: 1, Y: 1}}}}},
+		You:   Battlesnake{ID: "a", Health: 10, Body: []Coord{{X: 1, Y: 1}}},
+	}
+	body := mustMarshal(t, small)
+
+	state := AcquireGameState()
+	if err := DecodeGameState(bytes.NewReader(mustMarshal(t, sampleState(11, 4))), 0, state); err != nil {
+		t.Fatal(err)
+	}
+	ReleaseGameState(state)
+
+	state = AcquireGameState()
+	defer ReleaseGameState(state)
+	if err := DecodeGameState(bytes.NewReader(body), 0, state); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustMarshal(t, state); !bytes.Equal(got, body) {
+		t.Errorf("decoded\n%s\nwant\n%s", got, body)
+	}
+}
+
+// Benchmarks
+
+// BenchmarkDecodeReadAll decodes a move request the way handlers used to,
+// with io.ReadAll and a fresh state
+func BenchmarkDecodeReadAll(b *testing.B) {
+	for _, board := range benchBoards {
+		body := mustMarshal(b, sampleState(board.size, board.snakes))
+
+		b.Run(fmt.Sprintf("%dx%d", board.size, board.size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for n := 0; n < b.N; n++ {
+				data, _ := io.ReadAll(bytes.NewReader(body))
+				state := &GameState{}
+				if err := state.UnmarshalJSON(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDecodeGameState decodes a move request through DecodeGameState
+// into a pooled state
+func BenchmarkDecodeGameState(b *testing.B) {
+	for _, board := range benchBoards {
+		body := mustMarshal(b, sampleState(board.size, board.snakes))
+
+		b.Run(fmt.Sprintf("%dx%d", board.size, board.size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for n := 0; n < b.N; n++ {
+				state := AcquireGameState()
+				if err := DecodeGameState(bytes.NewReader(body), 0, state); err != nil {
+					b.Fatal(err)
+				}
+				ReleaseGameState(state)
+			}
+		})
+	}
+}
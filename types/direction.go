@@ -0,0 +1,122 @@
+package types
+
+import (
+	"fmt"
+)
+
+// Direction is a move on the board. Its text, and so its JSON, is the one
+// of BattlesnakeMoveResponse.Move. The zero value is no direction at all.
+type Direction uint8
+
+const (
+	NoDirection Direction = iota
+	Up
+	Down
+	Left
+	Right
+)
+
+// Every valid direction, in the order moves are tried
+var directions = [...]Direction{Up, Down, Left, Right}
+
+// Directions returns every valid direction, in the order moves are tried.
+// The slice is new on every call, callers are free to change it.
+func Directions() []Direction {
+	return append([]Direction(nil), directions[:]...)
+}
+
+// AllDirections returns every valid direction, in the order moves are tried.
+// It is a copy of an array, so it costs nothing to range over in hot paths.
+func AllDirections() [4]Direction {
+	return directions
+}
+
+var directionNames = [...]string{
+	Up:    "up",
+	Down:  "down",
+	Left:  "left",
+	Right: "right",
+}
+
+var directionDeltas = [...]Coord{
+	Up:    {X: 0, Y: 1},
+	Down:  {X: 0, Y: -1},
+	Left:  {X: -1, Y: 0},
+	Right: {X: 1, Y: 0},
+}
+
+var opposites = [...]Direction{
+	Up:    Down,
+	Down:  Up,
+	Left:  Right,
+	Right: Left,
+}
+
+func ParseDirection(s string) (Direction, error) {
+	for _, d := range directions {
+		if directionNames[d] == s {
+			return d, nil
+		}
+	}
+	return NoDirection, fmt.Errorf("invalid direction %q", s)
+}
+
+func (d Direction) Valid() bool {
+	return d >= Up && d <= Right
+}
+
+// String returns the move as the API spells it, or "" when d is not valid
+func (d Direction) String() string {
+	if !d.Valid() {
+		return ""
+	}
+	return directionNames[d]
+}
+
+// Delta is the change in coordinates one step in direction d makes
+func (d Direction) Delta() Coord {
+	if !d.Valid() {
+		return Coord{}
+	}
+	return directionDeltas[d]
+}
+
+func (d Direction) Opposite() Direction {
+	if !d.Valid() {
+		return NoDirection
+	}
+	return opposites[d]
+}
+
+// Apply returns the cell one step from coord in direction d. It knows
+// nothing of the board, the result may be off it.
+func (d Direction) Apply(coord Coord) Coord {
+	delta := d.Delta()
+	return Coord{X: coord.X + delta.X, Y: coord.Y + delta.Y}
+}
+
+func (d Direction) MarshalText() ([]byte, error) {
+	if !d.Valid() {
+		return nil, fmt.Errorf("invalid direction %d", uint8(d))
+	}
+	return []byte(d.String()), nil
+}
+
+func (d *Direction) UnmarshalText(text []byte) error {
+	parsed, err := ParseDirection(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Neighbors returns the four cells next to c, in the order of Directions.
+// Like Apply, it knows nothing of the board.
+func (c Coord) Neighbors() [4]Coord {
+	var neighbors [4]Coord
+	for i, d := range directions {
+		neighbors[i] = d.Apply(c)
+	}
+	return neighbors
+}
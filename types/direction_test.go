@@ -0,0 +1,124 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDirection(t *testing.T) {
+	tests := []struct {
+		text string
+		want Direction
+		ok   bool
+	}{
+		{text: "up", want: Up, ok: true},
+		{text: "down", want: Down, ok: true},
+		{text: "left", want: Left, ok: true},
+		{text: "right", want: Right, ok: true},
+		{text: "Up"},
+		{text: " up"},
+		{text: ""},
+		{text: "sideways"},
+	}
+
+	for _, test := range tests {
+		got, err := ParseDirection(test.text)
+		if test.ok && (err != nil || got != test.want) {
+			t.Errorf("%q: got %v, %v, want %v", test.text, got, err, test.want)
+		}
+		if !test.ok && (err == nil || got != NoDirection) {
+			t.Errorf("%q: got %v, %v, want an error", test.text, got, err)
+		}
+	}
+}
+
+func TestDirectionText(t *testing.T) {
+	for _, d := range AllDirections() {
+		text, err := d.MarshalText()
+		if err != nil || string(text) != d.String() {
+			t.Errorf("%v: marshalled %q, %v", d, text, err)
+		}
+
+		var back Direction
+		if err := back.UnmarshalText(text); err != nil || back != d {
+			t.Errorf("%q: unmarshalled %v, %v", text, back, err)
+		}
+	}
+
+	for _, d := range []Direction{NoDirection, Right + 1} {
+		if _, err := d.MarshalText(); err == nil {
+			t.Errorf("%d: marshalled without an error", uint8(d))
+		}
+		if d.String() != "" {
+			t.Errorf("%d: spelled %q", uint8(d), d.String())
+		}
+	}
+
+	var d Direction
+	if err := d.UnmarshalText([]byte("sideways")); err == nil || d != NoDirection {
+		t.Errorf("sideways: unmarshalled %v, %v", d, err)
+	}
+
+	// As a field, a Direction reads like BattlesnakeMoveResponse.Move
+	var move struct {
+		Move Direction `json:"move"`
+	}
+	if err := json.Unmarshal([]byte(`{"move": "left"}`), &move); err != nil || move.Move != Left {
+		t.Errorf("unmarshalled %v, %v", move.Move, err)
+	}
+	data, err := json.Marshal(move)
+	if err != nil || string(data) != `{"move":"left"}` {
+		t.Errorf("marshalled %s, %v", data, err)
+	}
+}
+
+func TestDirectionOpposite(t *testing.T) {
+	tests := []struct {
+		d, want Direction
+	}{
+		{d: Up, want: Down},
+		{d: Down, want: Up},
+		{d: Left, want: Right},
+		{d: Right, want: Left},
+		{d: NoDirection, want: NoDirection},
+		{d: Right + 1, want: NoDirection},
+	}
+
+	for _, test := range tests {
+		if got := test.d.Opposite(); got != test.want {
+			t.Errorf("opposite of %d: got %d, want %d", test.d, got, test.want)
+		}
+		if test.d.Valid() {
+			if back := test.d.Apply(test.d.Opposite().Apply(Coord{X: 3, Y: 3})); back != (Coord{X: 3, Y: 3}) {
+				t.Errorf("%v and back ends on %v", test.d, back)
+			}
+		}
+	}
+}
+
+func TestCoordNeighbors(t *testing.T) {
+	want := [4]Coord{{X: 2, Y: 6}, {X: 2, Y: 4}, {X: 1, Y: 5}, {X: 3, Y: 5}}
+	if got := (Coord{X: 2, Y: 5}).Neighbors(); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Off the board is up to the caller
+	want = [4]Coord{{X: 0, Y: 1}, {X: 0, Y: -1}, {X: -1, Y: 0}, {X: 1, Y: 0}}
+	if got := (Coord{}).Neighbors(); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for i, d := range AllDirections() {
+		if got := (Coord{X: 2, Y: 5}).Neighbors()[i]; got != d.Apply(Coord{X: 2, Y: 5}) {
+			t.Errorf("neighbor %d is %v, want it %v", i, got, d)
+		}
+	}
+}
+
+func TestDirectionsCopy(t *testing.T) {
+	moves := Directions()
+	moves[0] = Right
+	if Directions()[0] != Up || AllDirections()[0] != Up {
+		t.Error("changing the slice changed the directions")
+	}
+}
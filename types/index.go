@@ -0,0 +1,151 @@
+package types
+
+// Index answers what is on a cell of the board in constant time, where
+// looking through the lists of a GameState takes a scan per question. It is
+// built once from a state and does not follow it: after a move, build a new
+// one.
+type Index struct {
+	Width  int
+	Height int
+
+	cells []cell
+}
+
+type cell struct {
+	// Index in Board.Snakes plus one, 0 when no snake is on the cell
+	snake int32
+	// Index in the body of that snake, 0 for its head
+	segment int32
+	// Number of snakes with a segment on the cell
+	snakes int32
+	// Index in Board.Snakes plus one of the snake whose head is on the cell
+	head int32
+	// Number of hazards stacked on the cell
+	hazards int32
+	food    bool
+}
+
+// NewIndex indexes the board of state. Cells off the board are left out. A
+// snake stacked on itself, like at the start of a game, is indexed by the
+// segment closest to its head. When snakes overlap, the last one in
+// Board.Snakes wins.
+func NewIndex(state *GameState) *Index {
+	board := &state.Board
+
+	ix := &Index{
+		Width:  board.Width,
+		Height: board.Height,
+	}
+	if ix.Width > 0 && ix.Height > 0 {
+		ix.cells = make([]cell, ix.Width*ix.Height)
+	}
+
+	for _, coord := range board.Food {
+		if c := ix.at(coord); c != nil {
+			c.food = true
+		}
+	}
+
+	for _, coord := range board.Hazards {
+		if c := ix.at(coord); c != nil {
+			c.hazards++
+		}
+	}
+
+	for i, snake := range board.Snakes {
+		// From the tail up, so stacked segments end up indexed by the one
+		// closest to the head
+		for j := len(snake.Body) - 1; j >= 0; j-- {
+			if c := ix.at(snake.Body[j]); c != nil {
+				if c.snake != int32(i+1) {
+					c.snakes++
+				}
+				c.snake = int32(i + 1)
+				c.segment = int32(j)
+			}
+		}
+		if len(snake.Body) > 0 {
+			if c := ix.at(snake.Body[0]); c != nil {
+				c.head = int32(i + 1)
+			}
+		}
+	}
+
+	return ix
+}
+
+// Helper functions
+
+func (ix *Index) at(coord Coord) *cell {
+	if !ix.InBounds(coord) {
+		return nil
+	}
+	return &ix.cells[coord.Y*ix.Width+coord.X]
+}
+
+// Main logic
+
+func (ix *Index) InBounds(coord Coord) bool {
+	return coord.X >= 0 && coord.Y >= 0 && coord.X < ix.Width && coord.Y < ix.Height
+}
+
+// Occupied reports whether a snake is on coord
+func (ix *Index) Occupied(coord Coord) bool {
+	c := ix.at(coord)
+	return c != nil && c.snake != 0
+}
+
+// Occupants returns the number of snakes with a segment on coord. Only one
+// of them is the Occupant, when there are more the board has to be looked
+// at to find the others.
+func (ix *Index) Occupants(coord Coord) int {
+	c := ix.at(coord)
+	if c == nil {
+		return 0
+	}
+	return int(c.snakes)
+}
+
+// Occupant returns the index in Board.Snakes of the snake on coord
+func (ix *Index) Occupant(coord Coord) (snake int, ok bool) {
+	snake, _, ok = ix.Segment(coord)
+	return snake, ok
+}
+
+// Segment returns the index in Board.Snakes of the snake on coord, and the
+// index of coord in its body
+func (ix *Index) Segment(coord Coord) (snake, segment int, ok bool) {
+	c := ix.at(coord)
+	if c == nil || c.snake == 0 {
+		return 0, 0, false
+	}
+	return int(c.snake - 1), int(c.segment), true
+}
+
+// HeadAt returns the index in Board.Snakes of the snake whose head is on
+// coord
+func (ix *Index) HeadAt(coord Coord) (snake int, ok bool) {
+	c := ix.at(coord)
+	if c == nil || c.head == 0 {
+		return 0, false
+	}
+	return int(c.head - 1), true
+}
+
+func (ix *Index) IsFood(coord Coord) bool {
+	c := ix.at(coord)
+	return c != nil && c.food
+}
+
+// Hazards returns the number of hazards stacked on coord
+func (ix *Index) Hazards(coord Coord) int {
+	c := ix.at(coord)
+	if c == nil {
+		return 0
+	}
+	return int(c.hazards)
+}
+
+func (ix *Index) IsHazard(coord Coord) bool {
+	return ix.Hazards(coord) > 0
+}
@@ -0,0 +1,120 @@
+package types
+
+import (
+	"testing"
+)
+
+// Helper functions
+
+func coords(cs ...[2]int) []Coord {
+	b := make([]Coord, len(cs))
+	for i, c := range cs {
+		b[i] = Coord{X: c[0], Y: c[1]}
+	}
+	return b
+}
+
+// Tests
+
+func TestIndexOccupancy(t *testing.T) {
+	state := &GameState{Board: Board{
+		Width:  5,
+		Height: 5,
+		Snakes: []Battlesnake{
+			// Stacked on itself, as at the start of a game
+			{ID: "a", Body: coords([2]int{0, 0}, [2]int{0, 0}, [2]int{0, 0})},
+			// Tail tucked under its own neck, as after eating
+			{ID: "b", Body: coords([2]int{2, 2}, [2]int{2, 3}, [2]int{3, 3}, [2]int{3, 3})},
+			// Head on the tail of b, as in a state put together by hand
+			{ID: "c", Body: coords([2]int{3, 3}, [2]int{4, 3}, [2]int{4, 4})},
+		},
+	}}
+	ix := NewIndex(state)
+
+	tests := []struct {
+		name      string
+		coord     Coord
+		snake     int
+		segment   int
+		occupants int
+		head      int // -1 for no head
+	}{
+		{name: "stacked", coord: Coord{X: 0, Y: 0}, snake: 0, segment: 0, occupants: 1, head: 0},
+		{name: "head", coord: Coord{X: 2, Y: 2}, snake: 1, segment: 0, occupants: 1, head: 1},
+		{name: "body", coord: Coord{X: 2, Y: 3}, snake: 1, segment: 1, occupants: 1, head: -1},
+		{name: "overlapping", coord: Coord{X: 3, Y: 3}, snake: 2, segment: 0, occupants: 2, head: 2},
+		{name: "tail", coord: Coord{X: 4, Y: 4}, snake: 2, segment: 2, occupants: 1, head: -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !ix.Occupied(test.coord) {
+				t.Fatal("not occupied")
+			}
+			snake, segment, ok := ix.Segment(test.coord)
+			if !ok || snake != test.snake || segment != test.segment {
+				t.Errorf("segment %d of snake %d, want segment %d of snake %d", segment, snake, test.segment, test.snake)
+			}
+			if got := ix.Occupants(test.coord); got != test.occupants {
+				t.Errorf("%d occupants, want %d", got, test.occupants)
+			}
+			head, ok := ix.HeadAt(test.coord)
+			if test.head < 0 && ok {
+				t.Errorf("head of snake %d, want none", head)
+			}
+			if test.head >= 0 && (!ok || head != test.head) {
+				t.Errorf("head of snake %d (%t), want snake %d", head, ok, test.head)
+			}
+		})
+	}
+
+	for _, coord := range []Coord{{X: 1, Y: 1}, {X: -1, Y: 0}, {X: 5, Y: 0}, {X: 0, Y: 5}} {
+		if ix.Occupied(coord) || ix.Occupants(coord) != 0 {
+			t.Errorf("%v occupied", coord)
+		}
+		if _, _, ok := ix.Segment(coord); ok {
+			t.Errorf("segment on %v", coord)
+		}
+	}
+}
+
+func TestIndexHazards(t *testing.T) {
+	state := &GameState{Board: Board{
+		Width:   3,
+		Height:  3,
+		Hazards: coords([2]int{1, 1}, [2]int{1, 1}, [2]int{1, 1}, [2]int{0, 2}, [2]int{3, 3}),
+		Food:    coords([2]int{1, 1}, [2]int{2, 0}),
+	}}
+	ix := NewIndex(state)
+
+	tests := []struct {
+		coord   Coord
+		hazards int
+		food    bool
+	}{
+		{coord: Coord{X: 1, Y: 1}, hazards: 3, food: true},
+		{coord: Coord{X: 0, Y: 2}, hazards: 1},
+		{coord: Coord{X: 2, Y: 0}, food: true},
+		{coord: Coord{X: 0, Y: 0}},
+		{coord: Coord{X: 3, Y: 3}}, // Off the board
+	}
+
+	for _, test := range tests {
+		if got := ix.Hazards(test.coord); got != test.hazards {
+			t.Errorf("%v: %d hazards, want %d", test.coord, got, test.hazards)
+		}
+		if got := ix.IsHazard(test.coord); got != (test.hazards > 0) {
+			t.Errorf("%v: hazard %t", test.coord, got)
+		}
+		if got := ix.IsFood(test.coord); got != test.food {
+			t.Errorf("%v: food %t, want %t", test.coord, got, test.food)
+		}
+	}
+}
+
+func TestIndexEmptyBoard(t *testing.T) {
+	ix := NewIndex(&GameState{Board: Board{Snakes: []Battlesnake{{ID: "a", Body: coords([2]int{0, 0})}}}})
+	if ix.Occupied(Coord{}) || ix.Hazards(Coord{}) != 0 {
+		t.Error("cells on a board of no size")
+	}
+}
@@ -0,0 +1,153 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Invariant is a rule a GameState has to follow for strategies to make sense
+// of it
+type Invariant string
+
+const (
+	InvalidDimensions Invariant = "invalid dimensions"
+	OutOfBounds       Invariant = "out of bounds"
+	EmptyBody         Invariant = "empty body"
+	HeadMismatch      Invariant = "head is not the first body segment"
+	LengthMismatch    Invariant = "length is not the body length"
+	DuplicateSnake    Invariant = "duplicate snake"
+	MissingYou        Invariant = "you not on the board"
+)
+
+// Violation is one place a GameState breaks an invariant
+type Violation struct {
+	Invariant Invariant
+	Field     string // Path to the offending field, like board.snakes[1].body[3]
+	Detail    string
+}
+
+func (v Violation) String() string {
+	if v.Detail == "" {
+		return v.Field + ": " + string(v.Invariant)
+	}
+	return v.Field + ": " + string(v.Invariant) + " (" + v.Detail + ")"
+}
+
+// ValidationError lists every invariant a GameState breaks
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	violations := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		violations[i] = v.String()
+	}
+	return "invalid game state: " + strings.Join(violations, "; ")
+}
+
+// Has reports whether the invariant is one of those broken
+func (e *ValidationError) Has(invariant Invariant) bool {
+	for _, v := range e.Violations {
+		if v.Invariant == invariant {
+			return true
+		}
+	}
+	return false
+}
+
+type validator struct {
+	state      *GameState
+	violations []Violation
+}
+
+func (v *validator) fail(invariant Invariant, field, detail string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{
+		Invariant: invariant,
+		Field:     field,
+		Detail:    fmt.Sprintf(detail, args...),
+	})
+}
+
+func (v *validator) coord(field string, c Coord) {
+	board := v.state.Board
+	if c.X < 0 || c.Y < 0 || c.X >= board.Width || c.Y >= board.Height {
+		v.fail(OutOfBounds, field, "(%d,%d) on a %dx%d board", c.X, c.Y, board.Width, board.Height)
+	}
+}
+
+// snake checks a snake and fills in its Head and Length when missing
+func (v *validator) snake(field string, snake *Battlesnake) {
+	if len(snake.Body) == 0 {
+		v.fail(EmptyBody, field+".body", "")
+		return
+	}
+
+	for i, c := range snake.Body {
+		v.coord(fmt.Sprintf("%s.body[%d]", field, i), c)
+	}
+
+	if snake.Head == (Coord{}) {
+		snake.Head = snake.Body[0]
+	} else if snake.Head != snake.Body[0] {
+		v.fail(HeadMismatch, field+".head", "(%d,%d) but the body starts at (%d,%d)",
+			snake.Head.X, snake.Head.Y, snake.Body[0].X, snake.Body[0].Y)
+	}
+
+	if snake.Length == 0 {
+		snake.Length = len(snake.Body)
+	} else if snake.Length != len(snake.Body) {
+		v.fail(LengthMismatch, field+".length", "%d for a body of %d", snake.Length, len(snake.Body))
+	}
+}
+
+// Normalize checks the state before it goes to a strategy. Missing Head and
+// Length are filled in from the body, and You is rebuilt from the snake on
+// the board with the same ID. The returned *ValidationError lists every
+// invariant broken, the state is normalized as far as it could be.
+func (state *GameState) Normalize() error {
+	v := &validator{state: state}
+
+	if state.Board.Width <= 0 || state.Board.Height <= 0 {
+		v.fail(InvalidDimensions, "board", "%dx%d", state.Board.Width, state.Board.Height)
+		// Nothing can be in range of a board with no cells
+		return &ValidationError{Violations: v.violations}
+	}
+
+	for i, c := range state.Board.Food {
+		v.coord(fmt.Sprintf("board.food[%d]", i), c)
+	}
+	for i, c := range state.Board.Hazards {
+		v.coord(fmt.Sprintf("board.hazards[%d]", i), c)
+	}
+
+	you := -1
+	seen := make(map[string]bool, len(state.Board.Snakes))
+	for i := range state.Board.Snakes {
+		snake := &state.Board.Snakes[i]
+		field := fmt.Sprintf("board.snakes[%d]", i)
+
+		if seen[snake.ID] {
+			v.fail(DuplicateSnake, field+".id", "%q", snake.ID)
+		}
+		seen[snake.ID] = true
+
+		v.snake(field, snake)
+		if snake.ID == state.You.ID && you < 0 {
+			you = i
+		}
+	}
+
+	if you < 0 {
+		v.fail(MissingYou, "you.id", "%q", state.You.ID)
+		v.snake("you", &state.You)
+	} else {
+		state.You = state.Board.Snakes[you]
+		state.You.Body = append([]Coord(nil), state.You.Body...)
+	}
+
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+	return nil
+}
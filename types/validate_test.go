@@ -0,0 +1,163 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Helper functions
+
+// validState is a small state that breaks no invariant, for tests to break
+// one at a time
+func validState() *GameState {
+	body := func(coords ...Coord) []Coord { return coords }
+	return &GameState{
+		Board: Board{
+			Width:   7,
+			Height:  7,
+			Food:    []Coord{{X: 3, Y: 3}},
+			Hazards: []Coord{{X: 0, Y: 6}},
+			Snakes: []Battlesnake{
+				{ID: "a", Health: 90, Body: body(Coord{X: 1, Y: 2}, Coord{X: 1, Y: 1}, Coord{X: 1, Y: 0})},
+				{ID: "b", Health: 80, Body: body(Coord{X: 5, Y: 4}, Coord{X: 5, Y: 5}, Coord{X: 5, Y: 6})},
+			},
+		},
+		You: Battlesnake{ID: "a"},
+	}
+}
+
+// Tests
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(state *GameState)
+		want   []Violation
+	}{
+		{
+			name:   "valid",
+			modify: func(state *GameState) {},
+		},
+		{
+			name:   "invalid dimensions",
+			modify: func(state *GameState) { state.Board.Width = 0 },
+			want:   []Violation{{Invariant: InvalidDimensions, Field: "board", Detail: "0x7"}},
+		},
+		{
+			name:   "food out of bounds",
+			modify: func(state *GameState) { state.Board.Food[0] = Coord{X: 7, Y: 0} },
+			want:   []Violation{{Invariant: OutOfBounds, Field: "board.food[0]", Detail: "(7,0) on a 7x7 board"}},
+		},
+		{
+			name:   "hazard out of bounds",
+			modify: func(state *GameState) { state.Board.Hazards[0] = Coord{X: 0, Y: -1} },
+			want:   []Violation{{Invariant: OutOfBounds, Field: "board.hazards[0]", Detail: "(0,-1) on a 7x7 board"}},
+		},
+		{
+			name:   "body out of bounds",
+			modify: func(state *GameState) { state.Board.Snakes[1].Body[2] = Coord{X: 5, Y: 7} },
+			want:   []Violation{{Invariant: OutOfBounds, Field: "board.snakes[1].body[2]", Detail: "(5,7) on a 7x7 board"}},
+		},
+		{
+			name:   "empty body",
+			modify: func(state *GameState) { state.Board.Snakes[1].Body = nil },
+			want:   []Violation{{Invariant: EmptyBody, Field: "board.snakes[1].body"}},
+		},
+		{
+			name:   "head mismatch",
+			modify: func(state *GameState) { state.Board.Snakes[1].Head = Coord{X: 4, Y: 4} },
+			want:   []Violation{{Invariant: HeadMismatch, Field: "board.snakes[1].head", Detail: "(4,4) but the body starts at (5,4)"}},
+		},
+		{
+			name:   "length mismatch",
+			modify: func(state *GameState) { state.Board.Snakes[1].Length = 4 },
+			want:   []Violation{{Invariant: LengthMismatch, Field: "board.snakes[1].length", Detail: "4 for a body of 3"}},
+		},
+		{
+			name:   "duplicate snake",
+			modify: func(state *GameState) { state.Board.Snakes[1].ID = "a" },
+			want:   []Violation{{Invariant: DuplicateSnake, Field: "board.snakes[1].id", Detail: `"a"`}},
+		},
+		{
+			name: "missing you",
+			modify: func(state *GameState) {
+				state.You = state.Board.Snakes[0]
+				state.Board.Snakes = state.Board.Snakes[1:]
+			},
+			want: []Violation{{Invariant: MissingYou, Field: "you.id", Detail: `"a"`}},
+		},
+		{
+			name: "missing you with no body",
+			modify: func(state *GameState) {
+				state.Board.Snakes = state.Board.Snakes[1:]
+			},
+			want: []Violation{
+				{Invariant: MissingYou, Field: "you.id", Detail: `"a"`},
+				{Invariant: EmptyBody, Field: "you.body"},
+			},
+		},
+		{
+			name: "every violation is listed",
+			modify: func(state *GameState) {
+				state.Board.Food[0] = Coord{X: -1, Y: 0}
+				state.Board.Snakes[0].Length = 2
+				state.Board.Snakes[1].Body = nil
+			},
+			want: []Violation{
+				{Invariant: OutOfBounds, Field: "board.food[0]", Detail: "(-1,0) on a 7x7 board"},
+				{Invariant: LengthMismatch, Field: "board.snakes[0].length", Detail: "2 for a body of 3"},
+				{Invariant: EmptyBody, Field: "board.snakes[1].body"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := validState()
+			test.modify(state)
+
+			err := state.Normalize()
+			if test.want == nil {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("got %v, want a *ValidationError", err)
+			}
+			if !reflect.DeepEqual(invalid.Violations, test.want) {
+				t.Errorf("got %+v, want %+v", invalid.Violations, test.want)
+			}
+			for _, v := range test.want {
+				if !invalid.Has(v.Invariant) {
+					t.Errorf("Has(%q) is false", v.Invariant)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeFillsIn(t *testing.T) {
+	state := validState()
+	if err := state.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	snake := state.Board.Snakes[1]
+	if snake.Head != snake.Body[0] || snake.Length != len(snake.Body) {
+		t.Errorf("head %v length %d, want %v and %d", snake.Head, snake.Length, snake.Body[0], len(snake.Body))
+	}
+
+	// You is rebuilt from the board, with a body of its own
+	if !reflect.DeepEqual(state.You, state.Board.Snakes[0]) {
+		t.Errorf("you %+v, want %+v", state.You, state.Board.Snakes[0])
+	}
+	state.You.Body[0] = Coord{X: 6, Y: 6}
+	if state.Board.Snakes[0].Body[0] == state.You.Body[0] {
+		t.Error("you shares its body with the board")
+	}
+}
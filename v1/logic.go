@@ -1,66 +1,65 @@
 package v1
 
 import (
+	"context"
+	"math/rand"
 	"time"
 
+	"github.com/samyfodil/tb_library_snake_001/rng"
+	"github.com/samyfodil/tb_library_snake_001/rules"
 	"github.com/samyfodil/tb_library_snake_001/types"
 )
 
-func info() types.BattlesnakeInfoResponse {
-	return types.BattlesnakeInfoResponse{
-		APIVersion: "1",
-		Author:     "",        // TODO: Your types.Battlesnake username
-		Color:      "#099a40", // TODO: Choose color
-		Head:       "fang",    // TODO: Choose head
-		Tail:       "bolt",    // TODO: Choose tail
-	}
-}
+// Fixed order to walk moves in, iterating over a map would make the
+// chosen move change from one run to the next
+var allMoves = types.Directions()
 
 // move is called on every turn and returns your next move
 // Valid moves are "up", "down", "left", or "right"
 // See https://docs.battlesnake.com/api/example-move for available data
 func Domove(state *types.GameState) types.BattlesnakeMoveResponse {
 
-	isMoveSafe := map[string]bool{
-		"up":    true,
-		"down":  true,
-		"left":  true,
-		"right": true,
+	isMoveSafe := map[types.Direction]bool{
+		types.Up:    true,
+		types.Down:  true,
+		types.Left:  true,
+		types.Right: true,
 	}
 
+	geo := rules.GeometryOf(state)
 	myHead := state.You.Body[0] // types.Coordinates of your head
 
-	// Prevent your types.Battlesnake from moving out of bounds
-	boardWidth := state.Board.Width
+	// Neighboring cells, wrapped around the edges when the ruleset says so
+	up, down := geo.Move(myHead, types.Up), geo.Move(myHead, types.Down)
+	left, right := geo.Move(myHead, types.Left), geo.Move(myHead, types.Right)
 
-	if myHead.X == boardWidth-1 {
-		isMoveSafe["right"] = false
-	} else if myHead.X == 0 {
-		isMoveSafe["left"] = false
+	// Prevent your types.Battlesnake from moving out of bounds
+	if !geo.InBounds(right) {
+		isMoveSafe[types.Right] = false
+	} else if !geo.InBounds(left) {
+		isMoveSafe[types.Left] = false
 	}
 
-	boardHeight := state.Board.Height
-
-	if myHead.Y == 0 {
-		isMoveSafe["down"] = false
-	} else if myHead.Y == boardHeight-1 {
-		isMoveSafe["up"] = false
+	if !geo.InBounds(down) {
+		isMoveSafe[types.Down] = false
+	} else if !geo.InBounds(up) {
+		isMoveSafe[types.Up] = false
 	}
 
 	// Prevent your types.Battlesnake from colliding with itself
 	for _, cor := range state.You.Body[1:] /*skip head*/ {
 		x, y := cor.X, cor.Y
 
-		if myHead.X+1 == x {
-			isMoveSafe["right"] = false
-		} else if myHead.X-1 == x {
-			isMoveSafe["left"] = false
+		if right.X == x {
+			isMoveSafe[types.Right] = false
+		} else if left.X == x {
+			isMoveSafe[types.Left] = false
 		}
 
-		if myHead.Y-1 == y {
-			isMoveSafe["down"] = false
-		} else if myHead.Y+1 == y {
-			isMoveSafe["up"] = false
+		if down.Y == y {
+			isMoveSafe[types.Down] = false
+		} else if up.Y == y {
+			isMoveSafe[types.Up] = false
 		}
 	}
 
@@ -69,55 +68,43 @@ func Domove(state *types.GameState) types.BattlesnakeMoveResponse {
 		for _, cor := range snk.Body {
 			x, y := cor.X, cor.Y
 
-			if myHead.X+1 == x {
-				isMoveSafe["right"] = false
-			} else if myHead.X-1 == x {
-				isMoveSafe["left"] = false
+			if right.X == x {
+				isMoveSafe[types.Right] = false
+			} else if left.X == x {
+				isMoveSafe[types.Left] = false
 			}
 
-			if myHead.Y-1 == y {
-				isMoveSafe["down"] = false
-			} else if myHead.Y+1 == y {
-				isMoveSafe["up"] = false
+			if down.Y == y {
+				isMoveSafe[types.Down] = false
+			} else if up.Y == y {
+				isMoveSafe[types.Up] = false
 			}
 		}
 	}
 
 	// Are there any safe moves left?
-	safeMoves := []string{}
-	for move, isSafe := range isMoveSafe {
-		if isSafe {
+	safeMoves := []types.Direction{}
+	for _, move := range allMoves {
+		if isMoveSafe[move] {
 			safeMoves = append(safeMoves, move)
 		}
 	}
 
 	// if none, include all
 	if len(safeMoves) == 0 {
-		for move, _ := range isMoveSafe {
-			safeMoves = append(safeMoves, move)
-		}
+		safeMoves = append(safeMoves, allMoves...)
 	}
 
 	// Choose a random move from the safe ones
-	var nextMove string
+	var nextMove types.Direction
 
 	// Step 4 - Move towards food instead of random, to regain health and survive longer
-	scoredSafeMoves := make(map[string]int)
+	scoredSafeMoves := make(map[types.Direction]int)
 	for _, food := range state.Board.Food {
 		for _, smv := range safeMoves {
-			x, y := myHead.X, myHead.Y
-			switch smv {
-			case "up":
-				y++
-			case "down":
-				y--
-			case "right":
-				x++
-			case "left":
-				x--
-			}
+			dx, dy := geo.Delta(geo.Move(myHead, smv), food)
 			old_score := scoredSafeMoves[smv]
-			score := (food.X-x)*(food.X-x) + (food.Y-y)*(food.Y-y)
+			score := dx*dx + dy*dy
 			if score < old_score {
 				scoredSafeMoves[smv] = score
 			}
@@ -126,72 +113,58 @@ func Domove(state *types.GameState) types.BattlesnakeMoveResponse {
 
 	if len(scoredSafeMoves) > 0 {
 		best := state.Board.Width*state.Board.Width + state.Board.Height*state.Board.Height
-		for smv, score := range scoredSafeMoves {
-			if score < best {
+		for _, smv := range safeMoves {
+			score, ok := scoredSafeMoves[smv]
+			if ok && score < best {
 				best = score
 				nextMove = smv
 			}
 		}
 	} else {
-		nextMove = "down"
+		nextMove = types.Down
 	}
 
-	return types.BattlesnakeMoveResponse{Move: nextMove}
+	return types.BattlesnakeMoveResponse{Move: nextMove.String()}
 }
 
 func Domove2(state *types.GameState) types.BattlesnakeMoveResponse {
 
-	isMoveSafe := map[string]bool{
-		"up":    true,
-		"down":  true,
-		"left":  true,
-		"right": true,
+	isMoveSafe := map[types.Direction]bool{
+		types.Up:    true,
+		types.Down:  true,
+		types.Left:  true,
+		types.Right: true,
 	}
 
+	geo := rules.GeometryOf(state)
 	myHead := state.You.Body[0]
 	myNeck := state.You.Body[1]
 
-	if myNeck.X < myHead.X {
-		isMoveSafe["left"] = false
-
-	} else if myNeck.X > myHead.X {
-		isMoveSafe["right"] = false
-
-	} else if myNeck.Y < myHead.Y {
-		isMoveSafe["down"] = false
-
-	} else if myNeck.Y > myHead.Y {
-		isMoveSafe["up"] = false
+	if move := geo.Direction(myHead, myNeck); move.Valid() {
+		isMoveSafe[move] = false
 	}
 
-	boardWidth := state.Board.Width
-	boardHeight := state.Board.Height
-
 	// Prevent moving out of bounds
-	if myHead.X == 0 {
-		isMoveSafe["left"] = false
-	} else if myHead.X == boardWidth-1 {
-		isMoveSafe["right"] = false
-	}
-	if myHead.Y == 0 {
-		isMoveSafe["down"] = false
-	} else if myHead.Y == boardHeight-1 {
-		isMoveSafe["up"] = false
+	for move := range isMoveSafe {
+		if !geo.InBounds(geo.Move(myHead, move)) {
+			isMoveSafe[move] = false
+		}
 	}
 
 	// Prevent colliding with itself
 	for _, coord := range state.You.Body[1:] {
-		if coord.X == myHead.X {
-			if coord.Y < myHead.Y {
-				isMoveSafe["down"] = false
+		dx, dy := geo.Delta(myHead, coord)
+		if dx == 0 {
+			if dy < 0 {
+				isMoveSafe[types.Down] = false
 			} else {
-				isMoveSafe["up"] = false
+				isMoveSafe[types.Up] = false
 			}
-		} else if coord.Y == myHead.Y {
-			if coord.X < myHead.X {
-				isMoveSafe["left"] = false
+		} else if dy == 0 {
+			if dx < 0 {
+				isMoveSafe[types.Left] = false
 			} else {
-				isMoveSafe["right"] = false
+				isMoveSafe[types.Right] = false
 			}
 		}
 	}
@@ -199,36 +172,37 @@ func Domove2(state *types.GameState) types.BattlesnakeMoveResponse {
 	// Prevent colliding with other snakes
 	for _, snake := range state.Board.Snakes {
 		for _, coord := range snake.Body {
-			if coord.X == myHead.X {
-				if coord.Y < myHead.Y {
-					isMoveSafe["down"] = false
+			dx, dy := geo.Delta(myHead, coord)
+			if dx == 0 {
+				if dy < 0 {
+					isMoveSafe[types.Down] = false
 				} else {
-					isMoveSafe["up"] = false
+					isMoveSafe[types.Up] = false
 				}
-			} else if coord.Y == myHead.Y {
-				if coord.X < myHead.X {
-					isMoveSafe["left"] = false
+			} else if dy == 0 {
+				if dx < 0 {
+					isMoveSafe[types.Left] = false
 				} else {
-					isMoveSafe["right"] = false
+					isMoveSafe[types.Right] = false
 				}
 			}
 		}
 	}
 
-	safeMoves := []string{}
-	for move, isSafe := range isMoveSafe {
-		if isSafe {
+	safeMoves := []types.Direction{}
+	for _, move := range allMoves {
+		if isMoveSafe[move] {
 			safeMoves = append(safeMoves, move)
 		}
 	}
 
 	if len(safeMoves) == 0 {
-		return types.BattlesnakeMoveResponse{Move: "down"}
+		return types.BattlesnakeMoveResponse{Move: types.Down.String()}
 	}
 
 	// Move towards the closest food
-	closestFood := findClosestFood(state.You, state.Board)
-	moveTowardsFood := getMoveTowardsFood(state.You.Head, closestFood)
+	closestFood := findClosestFood(geo, state.You, state.Board)
+	moveTowardsFood := geo.Direction(state.You.Head, closestFood)
 
 	chosenMove := safeMoves[0]
 	for _, move := range safeMoves {
@@ -238,16 +212,16 @@ func Domove2(state *types.GameState) types.BattlesnakeMoveResponse {
 		}
 	}
 
-	return types.BattlesnakeMoveResponse{Move: chosenMove}
+	return types.BattlesnakeMoveResponse{Move: chosenMove.String()}
 
 }
 
-func findClosestFood(snake types.Battlesnake, board types.Board) types.Coord {
+func findClosestFood(geo rules.Geometry, snake types.Battlesnake, board types.Board) types.Coord {
 	closestFood := board.Food[0]
-	minDistance := manhattanDistance(snake.Head, closestFood)
+	minDistance := geo.Distance(snake.Head, closestFood)
 
 	for _, food := range board.Food {
-		distance := manhattanDistance(snake.Head, food)
+		distance := geo.Distance(snake.Head, food)
 		if distance < minDistance {
 			minDistance = distance
 			closestFood = food
@@ -258,45 +232,19 @@ func findClosestFood(snake types.Battlesnake, board types.Board) types.Coord {
 
 }
 
-func manhattanDistance(a, b types.Coord) int {
-	return abs(a.X-b.X) + abs(a.Y-b.Y)
-}
-
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
-
-func getMoveTowardsFood(head, food types.Coord) string {
-	if head.X < food.X {
-		return "right"
-	}
-	if head.X > food.X {
-		return "left"
-	}
-	if head.Y < food.Y {
-		return "up"
-	}
-	if head.Y > food.Y {
-		return "down"
-	}
-	return ""
-
-}
-
 func Domove3(state *types.GameState) types.BattlesnakeMoveResponse {
+	geo := rules.GeometryOf(state)
+
 	// Get safe moves
 	safeMoves := getSafeMoves(state)
 
 	if len(safeMoves) == 0 {
-		return types.BattlesnakeMoveResponse{Move: "down"}
+		return types.BattlesnakeMoveResponse{Move: types.Down.String()}
 	}
 
 	// Move towards the closest food
-	closestFood := findClosestFood(state.You, state.Board)
-	moveTowardsFood := getMoveTowardsFood(state.You.Head, closestFood)
+	closestFood := findClosestFood(geo, state.You, state.Board)
+	moveTowardsFood := geo.Direction(state.You.Head, closestFood)
 
 	chosenMove := safeMoves[0]
 	for _, move := range safeMoves {
@@ -306,22 +254,24 @@ func Domove3(state *types.GameState) types.BattlesnakeMoveResponse {
 		}
 	}
 
-	return types.BattlesnakeMoveResponse{Move: chosenMove}
+	return types.BattlesnakeMoveResponse{Move: chosenMove.String()}
 }
 
-func getSafeMoves(state *types.GameState) []string {
+func getSafeMoves(state *types.GameState) []types.Direction {
+	geo := rules.GeometryOf(state)
 	myHead := state.You.Body[0]
 	myBody := state.You.Body[1:]
+	ix := types.NewIndex(state)
 
-	possibleMoves := []string{"up", "down", "left", "right"}
-	safeMoves := []string{}
+	possibleMoves := types.AllDirections()
+	safeMoves := []types.Direction{}
 
 	for _, move := range possibleMoves {
-		newHead := getNewHead(myHead, move)
+		newHead := getNewHead(geo, myHead, move)
 		isSafe := true
 
 		// Check for wall collisions
-		if newHead.X < 0 || newHead.Y < 0 || newHead.X >= state.Board.Width || newHead.Y >= state.Board.Height {
+		if !geo.InBounds(newHead) {
 			isSafe = false
 		}
 
@@ -337,19 +287,8 @@ func getSafeMoves(state *types.GameState) []string {
 
 		// Check for opponent collisions
 		if isSafe {
-			for _, snake := range state.Board.Snakes {
-				if snake.ID == state.You.ID {
-					continue
-				}
-				for _, coord := range snake.Body {
-					if newHead.X == coord.X && newHead.Y == coord.Y {
-						isSafe = false
-						break
-					}
-				}
-				if !isSafe {
-					break
-				}
+			if snake, ok := ix.Occupant(newHead); ok && state.Board.Snakes[snake].ID != state.You.ID {
+				isSafe = false
 			}
 		}
 
@@ -361,8 +300,8 @@ func getSafeMoves(state *types.GameState) []string {
 	return safeMoves
 }
 
-func isOutOfBoard(width, height int, coord types.Coord) bool {
-	return coord.X < 0 || coord.X >= width || coord.Y < 0 || coord.Y >= height
+func isOutOfBoard(geo rules.Geometry, coord types.Coord) bool {
+	return !geo.InBounds(coord)
 }
 
 func isSnakeCollision(board types.Board, coord types.Coord) bool {
@@ -376,28 +315,17 @@ func isSnakeCollision(board types.Board, coord types.Coord) bool {
 	return false
 }
 
-func getNewHead(head types.Coord, move string) types.Coord {
-	newHead := types.Coord{X: head.X, Y: head.Y}
-	switch move {
-	case "up":
-		newHead.Y++
-	case "down":
-		newHead.Y--
-	case "left":
-		newHead.X--
-	case "right":
-		newHead.X++
-	}
-	return newHead
+func getNewHead(geo rules.Geometry, head types.Coord, move types.Direction) types.Coord {
+	return geo.Move(head, move)
 }
 
 /******************************/
 
-func getSafeMovesFromOpponents(myHead types.Coord, safeMoves []string, opponentMoves map[string][]types.Coord) []string {
-	safestMoves := make([]string, 0)
+func getSafeMovesFromOpponents(geo rules.Geometry, myHead types.Coord, safeMoves []types.Direction, opponentMoves map[string][]types.Coord) []types.Direction {
+	safestMoves := make([]types.Direction, 0)
 
 	for _, move := range safeMoves {
-		newHead := getNewHead(myHead, move)
+		newHead := getNewHead(geo, myHead, move)
 		isSafe := true
 
 		for _, opponentMoveSet := range opponentMoves {
@@ -421,11 +349,12 @@ func getSafeMovesFromOpponents(myHead types.Coord, safeMoves []string, opponentM
 }
 
 func getAllOpponentMoves(state *types.GameState) map[string][]types.Coord {
+	geo := rules.GeometryOf(state)
 	opponentMoves := make(map[string][]types.Coord)
 
 	for _, snake := range state.Board.Snakes {
 		if snake.ID != state.You.ID {
-			possibleMoves := getPossibleMoves(snake.Head)
+			possibleMoves := getPossibleMoves(geo, snake.Head)
 			opponentMoves[snake.ID] = possibleMoves
 		}
 	}
@@ -433,39 +362,40 @@ func getAllOpponentMoves(state *types.GameState) map[string][]types.Coord {
 	return opponentMoves
 }
 
-func getPossibleMoves(head types.Coord) []types.Coord {
+func getPossibleMoves(geo rules.Geometry, head types.Coord) []types.Coord {
 	return []types.Coord{
-		{X: head.X, Y: head.Y + 1},
-		{X: head.X, Y: head.Y - 1},
-		{X: head.X + 1, Y: head.Y},
-		{X: head.X - 1, Y: head.Y},
+		geo.Move(head, types.Up),
+		geo.Move(head, types.Down),
+		geo.Move(head, types.Right),
+		geo.Move(head, types.Left),
 	}
 }
 
 var lookAheadMoves = 8
 
 func Domove4(state *types.GameState) types.BattlesnakeMoveResponse {
+	geo := rules.GeometryOf(state)
 	myHead := state.You.Body[0]
 
 	// Get safe moves
 	safeMoves := getSafeMoves(state)
 
 	if len(safeMoves) == 0 {
-		return types.BattlesnakeMoveResponse{Move: "down"}
+		return types.BattlesnakeMoveResponse{Move: types.Down.String()}
 	}
 
 	// Get the positions of all possible moves for each opponent snake
 	opponentMoves := getAllOpponentMoves(state)
 
 	// Remove moves that would collide with opponents' possible moves
-	safestMoves := getSafeMovesFromOpponents(myHead, safeMoves, opponentMoves)
+	safestMoves := getSafeMovesFromOpponents(geo, myHead, safeMoves, opponentMoves)
 
 	chosenMove := safestMoves[0]
 
 	// If health is below the threshold, look for food
 	if state.You.Health < 50 {
-		closestFood := findClosestFood(state.You, state.Board)
-		moveTowardsFood := getMoveTowardsFood(state.You.Head, closestFood)
+		closestFood := findClosestFood(geo, state.You, state.Board)
+		moveTowardsFood := geo.Direction(state.You.Head, closestFood)
 
 		for _, move := range safestMoves {
 			if move == moveTowardsFood {
@@ -476,16 +406,17 @@ func Domove4(state *types.GameState) types.BattlesnakeMoveResponse {
 	}
 
 	// Simulate all possible moves for the next two turns and evaluate their safety
-	safestNextMoves := make([]string, 0)
+	r := rng.New(state)
+	safestNextMoves := make([]types.Direction, 0)
 	maxSafetyScore := -1
 
 	for _, move := range safestMoves {
 		simulatedState := simulateMove(state, move)
-		safetyScore, nextMove := getNextMoveSafetyScore(simulatedState, opponentMoves)
+		safetyScore, nextMove := getNextMoveSafetyScore(simulatedState, opponentMoves, r)
 
 		if safetyScore > maxSafetyScore {
 			maxSafetyScore = safetyScore
-			safestNextMoves = []string{nextMove}
+			safestNextMoves = []types.Direction{nextMove}
 		} else if safetyScore == maxSafetyScore {
 			safestNextMoves = append(safestNextMoves, nextMove)
 		}
@@ -496,7 +427,7 @@ func Domove4(state *types.GameState) types.BattlesnakeMoveResponse {
 		chosenMove = safestNextMoves[0]
 	}
 
-	return types.BattlesnakeMoveResponse{Move: chosenMove}
+	return types.BattlesnakeMoveResponse{Move: chosenMove.String()}
 }
 
 func deepCopyGameState(original *types.GameState) *types.GameState {
@@ -528,21 +459,22 @@ func deepCopyGameState(original *types.GameState) *types.GameState {
 	return copied
 }
 
-func simulateMove(state *types.GameState, move string) *types.GameState {
+func simulateMove(state *types.GameState, move types.Direction) *types.GameState {
 	simulatedState := deepCopyGameState(state)
-	newHead := getNewHead(simulatedState.You.Body[0], move)
+	newHead := getNewHead(rules.GeometryOf(state), simulatedState.You.Body[0], move)
 	simulatedState.You.Body = append([]types.Coord{newHead}, simulatedState.You.Body...)
 	return simulatedState
 }
 
-func getNextMoveSafetyScore(state *types.GameState, opponentMoves map[string][]types.Coord) (int, string) {
+func getNextMoveSafetyScore(state *types.GameState, opponentMoves map[string][]types.Coord, r *rand.Rand) (int, types.Direction) {
+	geo := rules.GeometryOf(state)
 	myHead := state.You.Body[0]
 	safeMoves := getSafeMoves(state)
 
-	safetyScores := make(map[string]int)
+	safetyScores := make(map[types.Direction]int)
 
 	for _, move := range safeMoves {
-		newHead := getNewHead(myHead, move)
+		newHead := getNewHead(geo, myHead, move)
 		isSafe := true
 
 		for _, opponentMoveSet := range opponentMoves {
@@ -565,15 +497,16 @@ func getNextMoveSafetyScore(state *types.GameState, opponentMoves map[string][]t
 	}
 
 	maxSafetyScore := -1
-	bestMove := ""
+	bestMove := types.NoDirection
 
-	for move, score := range safetyScores {
+	for _, move := range safeMoves {
+		score := safetyScores[move]
 		if score > maxSafetyScore {
 			maxSafetyScore = score
 			bestMove = move
 		} else if score == maxSafetyScore {
 			// If the random number is 0, update the bestMove
-			if time.Now().Unix()%2 == 0 {
+			if r.Intn(2) == 0 {
 				bestMove = move
 			}
 		}
@@ -586,66 +519,76 @@ func getNextMoveSafetyScore(state *types.GameState, opponentMoves map[string][]t
 
 // Move function
 func Domove5(state *types.GameState) types.BattlesnakeMoveResponse {
+	return Domove5Context(context.Background(), state)
+}
+
+// Domove5Context looks lookAheadMoves ahead, or as far as it gets before the
+// context is done
+func Domove5Context(ctx context.Context, state *types.GameState) types.BattlesnakeMoveResponse {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, maxCalculationTime)
+		defer cancel()
+	}
+
+	r := rng.New(state)
 	opponentMoves := getAllOpponentMoves(state)
-	safetyScore, chosenMove := getNextMoveSafetyScoreV2(state, opponentMoves)
+	lookAhead := lookAheadMoves
+	safetyScore, chosenMove := getNextMoveSafetyScoreV2(ctx, state, opponentMoves, lookAhead, r)
 
-	// Reduce lookAheadMoves until a move is found
-	for safetyScore == -1 && lookAheadMoves > 0 {
-		lookAheadMoves--
-		safetyScore, chosenMove = getNextMoveSafetyScoreV2(state, opponentMoves)
+	// Reduce the look ahead until a move is found, for this turn only so
+	// that the same state always gets the same answer
+	for safetyScore == -1 && lookAhead > 0 {
+		lookAhead--
+		safetyScore, chosenMove = getNextMoveSafetyScoreV2(ctx, state, opponentMoves, lookAhead, r)
 	}
 
 	// If no safe move is found, choose a random move from all possible moves
 	if safetyScore == -1 {
-		possibleMoves := []string{"up", "down", "left", "right"}
-		chosenMove = possibleMoves[int(time.Now().Unix())%len(possibleMoves)]
+		chosenMove = allMoves[r.Intn(len(allMoves))]
 	}
 
-	return types.BattlesnakeMoveResponse{Move: chosenMove}
+	return types.BattlesnakeMoveResponse{Move: chosenMove.String()}
 }
 
-func simulateMoveV2(state *types.GameState, move string, lookAheadMoves int) *types.GameState {
-	if lookAheadMoves <= 0 {
+func simulateMoveV2(ctx context.Context, state *types.GameState, move types.Direction, lookAheadMoves int) *types.GameState {
+	if lookAheadMoves <= 0 || ctx.Err() != nil {
 		return state
 	}
 
 	simulatedState := deepCopyGameState(state)
-	newHead := getNewHead(simulatedState.You.Body[0], move)
+	newHead := getNewHead(rules.GeometryOf(state), simulatedState.You.Body[0], move)
 	simulatedState.You.Body = append([]types.Coord{newHead}, simulatedState.You.Body[:len(simulatedState.You.Body)-1]...)
 
 	// Recursively simulate moves
 	opponentMoves := getAllOpponentMoves(simulatedState)
 	for _, moves := range opponentMoves {
 		for _, coord := range moves {
-			m := getDirection(simulatedState.You.Body[0], coord)
-			simulateMoveV2(simulatedState, m, lookAheadMoves-1)
+			m := getDirection(rules.GeometryOf(state), simulatedState.You.Body[0], coord)
+			simulateMoveV2(ctx, simulatedState, m, lookAheadMoves-1)
 		}
 	}
 
 	return simulatedState
 }
 
-func getDirection(currentHead, newHead types.Coord) string {
-	if newHead.X < currentHead.X {
-		return "left"
-	} else if newHead.X > currentHead.X {
-		return "right"
-	} else if newHead.Y < currentHead.Y {
-		return "down"
-	} else {
-		return "up"
+func getDirection(geo rules.Geometry, currentHead, newHead types.Coord) types.Direction {
+	if move := geo.Direction(currentHead, newHead); move.Valid() {
+		return move
 	}
+	return types.Up
 }
 
 // getNextMoveSafetyScoreV2 function
-func getNextMoveSafetyScoreV2(state *types.GameState, opponentMoves map[string][]types.Coord) (int, string) {
+func getNextMoveSafetyScoreV2(ctx context.Context, state *types.GameState, opponentMoves map[string][]types.Coord, lookAheadMoves int, r *rand.Rand) (int, types.Direction) {
+	geo := rules.GeometryOf(state)
 	myHead := state.You.Body[0]
 	safeMoves := getSafeMoves(state)
 
-	safetyScores := make(map[string]int)
+	safetyScores := make(map[types.Direction]int)
 
 	for _, move := range safeMoves {
-		newHead := getNewHead(myHead, move)
+		newHead := getNewHead(geo, myHead, move)
 		isSafe := true
 
 		for _, opponentMoveSet := range opponentMoves {
@@ -661,9 +604,9 @@ func getNextMoveSafetyScoreV2(state *types.GameState, opponentMoves map[string][
 		}
 
 		if isSafe {
-			simulatedState := simulateMoveV2(state, move, lookAheadMoves-1)
+			simulatedState := simulateMoveV2(ctx, state, move, lookAheadMoves-1)
 			opponentSimulatedMoves := getAllOpponentMoves(simulatedState)
-			safetyScore, _ := getNextMoveSafetyScore(simulatedState, opponentSimulatedMoves)
+			safetyScore, _ := getNextMoveSafetyScore(simulatedState, opponentSimulatedMoves, r)
 			safetyScores[move] = safetyScore + 1
 		} else {
 			safetyScores[move] = 0
@@ -671,15 +614,16 @@ func getNextMoveSafetyScoreV2(state *types.GameState, opponentMoves map[string][
 	}
 
 	maxSafetyScore := -1
-	bestMove := ""
+	bestMove := types.NoDirection
 
-	for move, score := range safetyScores {
+	for _, move := range safeMoves {
+		score := safetyScores[move]
 		if score > maxSafetyScore {
 			maxSafetyScore = score
 			bestMove = move
 		} else if score == maxSafetyScore {
 			// If the random number is 0, update the bestMove
-			if time.Now().Unix()%2 == 0 {
+			if r.Intn(2) == 0 {
 				bestMove = move
 			}
 		}
@@ -690,29 +634,42 @@ func getNextMoveSafetyScoreV2(state *types.GameState, opponentMoves map[string][
 
 /************************************************/
 
+// How long Domove5 and Domove6 search when the context has no deadline
 const maxCalculationTime = 30 * time.Millisecond
 
 func Domove6(state *types.GameState) types.BattlesnakeMoveResponse {
+	return Domove6Context(context.Background(), state)
+}
+
+// Domove6Context searches until it finds a move that does not collide or
+// the context is done
+func Domove6Context(ctx context.Context, state *types.GameState) types.BattlesnakeMoveResponse {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, maxCalculationTime)
+		defer cancel()
+	}
+
+	geo := rules.GeometryOf(state)
 	myHead := state.You.Body[0]
+	r := rng.New(state)
 	opponentMoves := getAllOpponentMoves(state)
-	var chosenMove string
+	var chosenMove types.Direction
 	var collisionDetected bool
 	var bestSafetyScore int = -1
 
-	startTime := time.Now()
-
 	for {
-		currentSafetyScore, currentMove := getNextMoveSafetyScoreV2(state, opponentMoves)
+		currentSafetyScore, currentMove := getNextMoveSafetyScoreV2(ctx, state, opponentMoves, lookAheadMoves, r)
 		if currentSafetyScore > bestSafetyScore {
 			bestSafetyScore = currentSafetyScore
 			chosenMove = currentMove
 		}
 
-		newHead := getNewHead(myHead, chosenMove)
+		newHead := getNewHead(geo, myHead, chosenMove)
 		collisionDetected = false
 
 		// Check for wall collisions
-		if newHead.X < 0 || newHead.Y < 0 || newHead.X >= state.Board.Width || newHead.Y >= state.Board.Height {
+		if !geo.InBounds(newHead) {
 			collisionDetected = true
 		}
 
@@ -744,10 +701,10 @@ func Domove6(state *types.GameState) types.BattlesnakeMoveResponse {
 			}
 		}
 
-		if !collisionDetected || time.Since(startTime) > maxCalculationTime {
+		if !collisionDetected || ctx.Err() != nil {
 			break
 		}
 	}
 
-	return types.BattlesnakeMoveResponse{Move: chosenMove}
+	return types.BattlesnakeMoveResponse{Move: chosenMove.String()}
 }
@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+const author = "samyfodil"
+
+func init() {
+	strategy.Register(strategy.Func{
+		StrategyName: "v1.1",
+		MoveFunc:     Domove,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#099a40",
+			Head:   "fang",
+			Tail:   "bolt",
+		},
+	}, "tau001")
+
+	strategy.Register(strategy.Func{
+		StrategyName: "v1.2",
+		MoveFunc:     Domove2,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#2e86de",
+			Head:   "beluga",
+			Tail:   "curled",
+		},
+	}, "tau002")
+
+	strategy.Register(strategy.Func{
+		StrategyName: "v1.3",
+		MoveFunc:     Domove3,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#e67e22",
+			Head:   "bendr",
+			Tail:   "hook",
+		},
+	}, "tau003")
+
+	strategy.Register(strategy.Func{
+		StrategyName: "v1.4",
+		MoveFunc:     Domove4,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#8e44ad",
+			Head:   "evil",
+			Tail:   "sharp",
+		},
+	}, "tau004")
+
+	strategy.Register(strategy.Func{
+		StrategyName: "v1.5",
+		SearchFunc:   Domove5Context,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#c0392b",
+			Head:   "pixel",
+			Tail:   "pixel",
+		},
+	}, "tau005")
+
+	strategy.Register(strategy.Func{
+		StrategyName: "v1.6",
+		SearchFunc:   Domove6Context,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#7f8c8d",
+			Head:   "safe",
+			Tail:   "round-bum",
+		},
+	}, "tau010")
+}
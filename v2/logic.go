@@ -1,35 +1,18 @@
 package v2
 
 import (
+	"context"
 	"math/rand"
-	"time"
 
+	"github.com/samyfodil/tb_library_snake_001/rng"
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/shout"
+	"github.com/samyfodil/tb_library_snake_001/strategy"
 	"github.com/samyfodil/tb_library_snake_001/types"
 )
 
 var LookStepsAhead = 2
 
-// Initialize the random seed
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
-// Helper functions
-
-func applyMove(head types.Coord, move string) types.Coord {
-	switch move {
-	case "up":
-		head.Y++
-	case "down":
-		head.Y--
-	case "left":
-		head.X--
-	case "right":
-		head.X++
-	}
-	return head
-}
-
 // Main logic
 
 func isCoordInList(coord types.Coord, list []types.Coord) bool {
@@ -41,21 +24,30 @@ func isCoordInList(coord types.Coord, list []types.Coord) bool {
 	return false
 }
 
-func predictSnakesNextPositions(state *types.GameState) types.Board {
+func predictSnakesNextPositions(state *types.GameState, ix *types.Index, r *rand.Rand) types.Board {
+	geo := rules.GeometryOf(state)
 	board := state.Board
+
+	// The snakes of state are shared with the states before it, and ix
+	// indexes them as they are before anyone moves
+	board.Snakes = append([]types.Battlesnake(nil), state.Board.Snakes...)
 	for i, snake := range board.Snakes {
 		// Skip dead snakes
-		if snake.Health <= 1 || (isCoordInList(snake.Body[0], state.Board.Hazards) && snake.Health <= 16) {
+		if snake.Health <= 1 || (ix.IsHazard(snake.Body[0]) && snake.Health <= 16) {
 			continue
 		}
 
-		safeMoves := getSafeMoves(state, snake.Head, snake.Body)
+		safeMoves := getSafeMoves(state, ix, snake.Head, snake.Body)
 		if len(safeMoves) > 0 {
-			move := safeMoves[rand.Intn(len(safeMoves))]
-			newHead := applyMove(snake.Head, move)
+			move := safeMoves[r.Intn(len(safeMoves))]
+			newHead := geo.Move(snake.Head, move)
 			board.Snakes[i].Body = append([]types.Coord{newHead}, snake.Body[:len(snake.Body)-1]...)
 
-			if isCoordInList(newHead, board.Food) {
+			if !rules.TailMoves(state) {
+				// Constrictor snakes grow every turn and never starve
+				board.Snakes[i].Body = append([]types.Coord{newHead}, snake.Body...)
+				board.Snakes[i].Health = rules.MaxHealth
+			} else if ix.IsFood(newHead) {
 				board.Snakes[i].Body = append([]types.Coord{newHead}, snake.Body...)
 				board.Snakes[i].Health = 100
 			} else {
@@ -69,20 +61,22 @@ func predictSnakesNextPositions(state *types.GameState) types.Board {
 	return board
 }
 
-func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord) []string {
-	board := state.Board
-	possibleMoves := []string{"up", "down", "left", "right"}
+func getSafeMoves(state *types.GameState, ix *types.Index, head types.Coord, body []types.Coord) []types.Direction {
+	geo := rules.GeometryOf(state)
+	possibleMoves := types.AllDirections()
 
 	// Initialize move scores
-	moveScores := make(map[string]int)
+	moveScores := make(map[types.Direction]int)
 
-	isHeadInHazard := isCoordInList(head, board.Hazards)
+	// In royale games cells about to turn into hazards count as hazards
+	forecast := rules.ForecastHazards(state)
+	isHeadInHazard := ix.IsHazard(head)
 
 	for _, move := range possibleMoves {
-		newHead := applyMove(head, move)
+		newHead := geo.Move(head, move)
 
 		// Check if the new head position is out of the board
-		if newHead.X < 0 || newHead.X >= board.Width || newHead.Y < 0 || newHead.Y >= board.Height {
+		if !geo.InBounds(newHead) {
 			moveScores[move] = -1000
 			continue
 		}
@@ -94,7 +88,7 @@ func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord)
 		}
 
 		// Check if the new head position is in another snake's body
-		if isCoordInSnakeLists(state, newHead) {
+		if isCoordInSnakeLists(state, ix, newHead) {
 			moveScores[move] = -1000
 			continue
 		}
@@ -105,7 +99,7 @@ func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord)
 				continue
 			}
 			for _, otherMove := range possibleMoves {
-				otherNewHead := applyMove(otherSnake.Head, otherMove)
+				otherNewHead := geo.Move(otherSnake.Head, otherMove)
 				if newHead == otherNewHead {
 					if len(otherSnake.Body) >= len(body) {
 						moveScores[move] = -1000
@@ -116,7 +110,7 @@ func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord)
 		}
 
 		// Check if the new head position is in a hazard
-		if isCoordInList(newHead, board.Hazards) {
+		if ix.IsHazard(newHead) || forecast.MayBeHazard(newHead, state.Turn+1) {
 			if !isHeadInHazard {
 				moveScores[move] = -500
 			} else {
@@ -130,9 +124,10 @@ func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord)
 	}
 
 	// Find the best move based on scores
-	bestMove := ""
+	bestMove := types.NoDirection
 	bestScore := -1001
-	for move, score := range moveScores {
+	for _, move := range possibleMoves {
+		score := moveScores[move]
 		if score > bestScore {
 			bestScore = score
 			bestMove = move
@@ -141,45 +136,39 @@ func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord)
 
 	// If there's no best move, return an empty slice
 	if bestScore <= -1000 {
-		return []string{}
+		return []types.Direction{}
 	}
 
-	return []string{bestMove}
+	return []types.Direction{bestMove}
 }
 
-func isCoordInSnakeLists(state *types.GameState, coord types.Coord) bool {
-	for _, snake := range state.Board.Snakes {
-		// Skip the dead snakes
-		if snake.Health <= 1 || (isCoordInList(snake.Body[0], state.Board.Hazards) && snake.Health <= 16) {
-			continue
-		}
+func isCoordInSnakeLists(state *types.GameState, ix *types.Index, coord types.Coord) bool {
+	// Skip the dead snakes
+	dead := func(snake types.Battlesnake) bool {
+		return snake.Health <= 1 || (ix.IsHazard(snake.Body[0]) && snake.Health <= 16)
+	}
+
+	switch ix.Occupants(coord) {
+	case 0:
+		return false
+	case 1:
+		snake, _ := ix.Occupant(coord)
+		return !dead(state.Board.Snakes[snake])
+	}
 
-		if isCoordInList(coord, snake.Body) {
+	// Predicted snakes can overlap, a dead one over one that is not
+	for _, snake := range state.Board.Snakes {
+		if !dead(snake) && isCoordInList(coord, snake.Body) {
 			return true
 		}
 	}
 	return false
 }
 
-func distance(a, b types.Coord) int {
-	return abs(a.X-b.X) + abs(a.Y-b.Y)
-}
-
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
-
-func countSegmentsInHazard(snake types.Battlesnake, board types.Board) int {
+func countSegmentsInHazard(snake types.Battlesnake, ix *types.Index) int {
 	segmentsInHazard := 0
 	for _, segment := range snake.Body {
-		for _, hazard := range board.Hazards {
-			if segment.X == hazard.X && segment.Y == hazard.Y {
-				segmentsInHazard++
-			}
-		}
+		segmentsInHazard += ix.Hazards(segment)
 	}
 	return segmentsInHazard
 }
@@ -196,16 +185,18 @@ func freeSpaceRatio(state *types.GameState) float64 {
 	return float64(freeSpaces) / float64(totalSpaces)
 }
 
-func shuffleMoves(moves []string) {
+func shuffleMoves(moves []types.Direction, r *rand.Rand) {
 	for i := len(moves) - 1; i > 0; i-- {
-		j := rand.Intn(i + 1)
+		j := r.Intn(i + 1)
 		moves[i], moves[j] = moves[j], moves[i]
 	}
 }
 
-func isSafeMove(newHead types.Coord, state *types.GameState) bool {
+func isSafeMove(newHead types.Coord, state *types.GameState, ix *types.Index) bool {
+	geo := rules.GeometryOf(state)
+
 	// Check if the new head position is within the board boundaries
-	if newHead.X < 0 || newHead.Y < 0 || newHead.X >= state.Board.Width || newHead.Y >= state.Board.Height {
+	if !geo.InBounds(newHead) {
 		return false
 	}
 
@@ -215,29 +206,23 @@ func isSafeMove(newHead types.Coord, state *types.GameState) bool {
 	}
 
 	// Check if the new head position collides with other snakes
-	for _, snake := range state.Board.Snakes {
-		// Skip the dead snakes
-		if snake.Health <= 1 || (isCoordInList(snake.Body[0], state.Board.Hazards) && snake.Health <= 16) {
-			continue
-		}
-
-		if isCoordInList(newHead, snake.Body) {
-			return false
-		}
+	if isCoordInSnakeLists(state, ix, newHead) {
+		return false
 	}
 
 	return true
 }
 
-func chooseBestMove(state *types.GameState, safeMoves []string) string {
+func chooseBestMove(state *types.GameState, ix *types.Index, safeMoves []types.Direction, r *rand.Rand) types.Direction {
+	geo := rules.GeometryOf(state)
 	myHead := state.You.Head
 	minDist := state.Board.Width*state.Board.Height + 1
 	maxDist := -1
 
-	bestMoves := []string{}
+	bestMoves := []types.Direction{}
 
 	// Calculate the number of snake body segments in the hazard area
-	segmentsInHazard := countSegmentsInHazard(state.You, state.Board)
+	segmentsInHazard := countSegmentsInHazard(state.You, ix)
 	hazardWeight := float64(segmentsInHazard) * 1.5 // Adjust the multiplier as needed to fine-tune the prioritization
 
 	// Calculate the free space ratio
@@ -263,10 +248,10 @@ func chooseBestMove(state *types.GameState, safeMoves []string) string {
 	}
 
 	for _, move := range safeMoves {
-		newHead := applyMove(myHead, move)
+		newHead := geo.Move(myHead, move)
 
 		// Check if the new head position is in a hazard
-		inHazard := isCoordInList(newHead, state.Board.Hazards)
+		inHazard := ix.IsHazard(newHead)
 
 		// Move out of the hazard area when more of the snake's body is in it
 		if inHazard {
@@ -278,16 +263,16 @@ func chooseBestMove(state *types.GameState, safeMoves []string) string {
 
 		// Keep the original logic for choosing the best move based on distance to food, but prioritize based on health
 		for _, food := range state.Board.Food {
-			dist := distance(newHead, food)
+			dist := geo.Distance(newHead, food)
 			if shouldGetFood {
 				// If the snake should get food, prioritize the moves that minimize the distance to food
-				if dist <= minDist && rand.Float64()*100 < float64(healthThreshold) {
+				if dist <= minDist && r.Float64()*100 < float64(healthThreshold) {
 					minDist = dist
 					bestMoves = append(bestMoves, move)
 				}
 			} else {
 				// If the snake should not get food, prioritize the moves that maximize the distance to food
-				if dist >= maxDist && rand.Float64()*100 < float64(100-healthThreshold) {
+				if dist >= maxDist && r.Float64()*100 < float64(100-healthThreshold) {
 					maxDist = dist
 					bestMoves = append(bestMoves, move)
 				}
@@ -296,12 +281,12 @@ func chooseBestMove(state *types.GameState, safeMoves []string) string {
 	}
 
 	// Shuffle the best moves list
-	shuffleMoves(bestMoves)
+	shuffleMoves(bestMoves, r)
 
 	// Find the first safe move from the shuffled list
 	for _, move := range bestMoves {
-		newHead := applyMove(myHead, move)
-		if isSafeMove(newHead, state) {
+		newHead := geo.Move(myHead, move)
+		if isSafeMove(newHead, state, ix) {
 			return move
 		}
 	}
@@ -310,30 +295,55 @@ func chooseBestMove(state *types.GameState, safeMoves []string) string {
 	return safeMoves[0]
 }
 
-func isMoveSafeAfterNSteps(state *types.GameState, move string, steps int) bool {
+// ix indexes the board of state
+func isMoveSafeAfterNSteps(ctx context.Context, state *types.GameState, ix *types.Index, move types.Direction, steps int, r *rand.Rand) bool {
+	geo := rules.GeometryOf(state)
+
 	if steps == 0 {
 		return true
 	}
 
+	// Out of time, the caller drops whatever this returns
+	if ctx.Err() != nil {
+		return false
+	}
+
 	// Apply the move to the current head position
-	newHead := applyMove(state.You.Head, move)
+	newHead := geo.Move(state.You.Head, move)
 
 	// Check if the new head position is inside the board
-	if newHead.X < 0 || newHead.X >= state.Board.Width || newHead.Y < 0 || newHead.Y >= state.Board.Height {
+	if !geo.InBounds(newHead) {
 		return false
 	}
 
-	// Create a new state where our snake has made the move
-	newBody := append([]types.Coord{newHead}, state.You.Body[:len(state.You.Body)-1]...)
-	newState := state
+	// Create a new state where our snake has made the move, the tail stays
+	// in place when the ruleset does not move it
+	keep := len(state.You.Body) - 1
+	if !rules.TailMoves(state) {
+		keep = len(state.You.Body)
+	}
+	newBody := append([]types.Coord{newHead}, state.You.Body[:keep]...)
+	next := *state
+	newState := &next
+	newState.Turn++
 	newState.You.Body = newBody
 	newState.You.Head = newHead
 
-	// Predict the next positions of all snakes, including our own
-	newState.Board = predictSnakesNextPositions(newState)
+	// Hazards the royale shrink may have added by now are as real as the
+	// ones already on the board
+	if forecast := rules.ForecastHazards(state); forecast.NextShrink() == newState.Turn {
+		newState.Board.Hazards = forecast.Hazards(newState.Turn)
+		ix = types.NewIndex(newState)
+	}
+
+	// Predict the next positions of all snakes, including our own. The
+	// index of the board they end up on is the one the next step starts
+	// from.
+	newState.Board = predictSnakesNextPositions(newState, ix, r)
+	nextIx := types.NewIndex(newState)
 
 	// Get the safe moves for the new state
-	safeMoves := getSafeMoves(newState, newState.You.Head, newState.You.Body)
+	safeMoves := getSafeMoves(newState, nextIx, newState.You.Head, newState.You.Body)
 
 	// If there are no safe moves left in the new state, the initial move is not safe
 	if len(safeMoves) == 0 {
@@ -342,7 +352,7 @@ func isMoveSafeAfterNSteps(state *types.GameState, move string, steps int) bool
 
 	// Check if the moves are safe after N-1 steps
 	for _, nextMove := range safeMoves {
-		if !isMoveSafeAfterNSteps(newState, nextMove, steps-1) {
+		if !isMoveSafeAfterNSteps(ctx, newState, nextIx, nextMove, steps-1, r) {
 			return false
 		}
 	}
@@ -351,24 +361,49 @@ func isMoveSafeAfterNSteps(state *types.GameState, move string, steps int) bool
 }
 
 func Move(state *types.GameState) types.BattlesnakeMoveResponse {
+	return MoveContext(context.Background(), state)
+}
+
+// MoveContext looks LookStepsAhead steps ahead, or as many as the
+// lookStepsAhead param says. Whatever that is, it stops when the context is
+// done and goes with the deepest look ahead finished by then.
+func MoveContext(ctx context.Context, state *types.GameState) types.BattlesnakeMoveResponse {
+	r := rng.New(state)
+	depth := strategy.ParamsFrom(ctx).Int("lookStepsAhead", LookStepsAhead)
+
 	// Get safe moves for our snake based on the current state
-	safeMoves := getSafeMoves(state, state.You.Head, state.You.Body)
+	ix := types.NewIndex(state)
+	safeMoves := getSafeMoves(state, ix, state.You.Head, state.You.Body)
+
+	// Filter out moves that would not be safe after N steps, one more step
+	// at a time so that there is something to go with when time runs out
+	safeMovesAfterNSteps := safeMoves
+	lookedAhead := 0
+	for steps := 1; steps <= depth; steps++ {
+		filtered := make([]types.Direction, 0, len(safeMoves))
+		for _, move := range safeMoves {
+			if isMoveSafeAfterNSteps(ctx, state, ix, move, steps, r) {
+				filtered = append(filtered, move)
+			}
+		}
 
-	// Filter out moves that would not be safe after N steps
-	safeMovesAfterNSteps := make([]string, 0, len(safeMoves))
-	for _, move := range safeMoves {
-		if isMoveSafeAfterNSteps(state, move, LookStepsAhead) {
-			safeMovesAfterNSteps = append(safeMovesAfterNSteps, move)
+		// A look ahead cut short proves nothing
+		if ctx.Err() != nil {
+			break
 		}
+		safeMovesAfterNSteps = filtered
+		lookedAhead = steps
 	}
 
 	// If there are no safe moves left after filtering, fall back to the initial safe moves
+	said := shout.LookedAhead(lookedAhead)
 	if len(safeMovesAfterNSteps) == 0 {
 		safeMovesAfterNSteps = safeMoves
+		said = shout.Format("nothing safe %d moves ahead", lookedAhead)
 	}
 
 	// Choose the best move based on your criteria (e.g., move towards food)
-	nextMove := chooseBestMove(state, safeMovesAfterNSteps)
+	nextMove := chooseBestMove(state, ix, safeMovesAfterNSteps, r)
 
-	return types.BattlesnakeMoveResponse{Move: nextMove}
+	return types.BattlesnakeMoveResponse{Move: nextMove.String(), Shout: said}
 }
@@ -0,0 +1,21 @@
+package v2
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+const author = "samyfodil"
+
+func init() {
+	strategy.Register(strategy.Func{
+		StrategyName: "v2",
+		SearchFunc:   MoveContext,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#16a085",
+			Head:   "smile",
+			Tail:   "skinny",
+		},
+	}, "tau006")
+}
@@ -1,50 +1,21 @@
 package v3
 
 import (
+	"context"
 	"sort"
 
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/shout"
+	"github.com/samyfodil/tb_library_snake_001/strategy"
 	"github.com/samyfodil/tb_library_snake_001/types"
 )
 
 // Helper functions
 
-func applyMove(head types.Coord, move string) types.Coord {
-	switch move {
-	case "up":
-		head.Y++
-	case "down":
-		head.Y--
-	case "left":
-		head.X--
-	case "right":
-		head.X++
-	}
-	return head
-}
-
-func getAdjacentCoords(coord types.Coord) []types.Coord {
-	return []types.Coord{
-		{coord.X, coord.Y + 1},
-		{coord.X, coord.Y - 1},
-		{coord.X - 1, coord.Y},
-		{coord.X + 1, coord.Y},
-	}
-}
-
-func isCoordInList(coord types.Coord, list []types.Coord) bool {
-	for _, c := range list {
-		if c == coord {
-			return true
-		}
-	}
-	return false
-}
-
-func isCoordInBounds(coord types.Coord, width int, height int) bool {
-	return coord.X >= 0 && coord.X < width && coord.Y >= 0 && coord.Y < height
-}
-
 func createBoard(state *types.GameState) [][]float64 {
+	geo := rules.GeometryOf(state)
+	ix := types.NewIndex(state)
+
 	board := make([][]float64, state.Board.Height)
 	for i := range board {
 		board[i] = make([]float64, state.Board.Width)
@@ -56,25 +27,25 @@ func createBoard(state *types.GameState) [][]float64 {
 			coord := types.Coord{X: x, Y: y}
 
 			// Score for snake bodies (including ours): 0
-			if isCoordInSnakeLists(state, coord) {
+			if ix.Occupied(coord) {
 				board[y][x] = 0
 				continue
 			}
 
 			// Score for food: 1
-			if isCoordInList(coord, state.Board.Food) {
+			if ix.IsFood(coord) {
 				board[y][x] = 1
 				continue
 			}
 
-			// Score for cells close to borders: 0.5
-			if x == 0 || x == state.Board.Width-1 || y == 0 || y == state.Board.Height-1 {
+			// Score for cells close to borders: 0.5, wrapped boards have none
+			if !geo.Wrapped && (x == 0 || x == state.Board.Width-1 || y == 0 || y == state.Board.Height-1) {
 				board[y][x] = 0.5
 				continue
 			}
 
 			// Score for hazard cells: 0 to 0.5
-			if isCoordInList(coord, state.Board.Hazards) {
+			if ix.IsHazard(coord) {
 				board[y][x] = 0.25 // You can adjust this value to control the hazard score
 				continue
 			}
@@ -87,23 +58,9 @@ func createBoard(state *types.GameState) [][]float64 {
 	return board
 }
 
-func isCoordInSnakeLists(state *types.GameState, coord types.Coord) bool {
-	for _, snake := range state.Board.Snakes {
-		if isCoordInList(coord, snake.Body) {
-			return true
-		}
-	}
-	return false
-}
-
-func nextMove(head types.Coord, board [][]float64) string {
-	// Filter out moves that would go out of bounds
-	width := len(board[0])
-	height := len(board)
-
-	adjacentCoords := filter(getAdjacentCoords(head), func(coord types.Coord) bool {
-		return isCoordInBounds(coord, width, height)
-	})
+func nextMove(geo rules.Geometry, head types.Coord, board [][]float64) types.Direction {
+	// Neighbors already leaves out moves that would go out of bounds
+	adjacentCoords := geo.Neighbors(head)
 
 	sort.Slice(adjacentCoords, func(i, j int) bool {
 		return board[adjacentCoords[i].Y][adjacentCoords[i].X] > board[adjacentCoords[j].Y][adjacentCoords[j].X]
@@ -111,38 +68,19 @@ func nextMove(head types.Coord, board [][]float64) string {
 	})
 
 	bestCoord := adjacentCoords[0]
-	if head.X < bestCoord.X {
-		return "right"
-	} else if head.X > bestCoord.X {
-		return "left"
-	} else if head.Y < bestCoord.Y {
-		return "up"
-	} else {
-		return "down"
-	}
-}
-
-func filter(coords []types.Coord, condition func(types.Coord) bool) []types.Coord {
-	filtered := make([]types.Coord, 0, len(coords))
-	for _, coord := range coords {
-		if condition(coord) {
-			filtered = append(filtered, coord)
-		}
-	}
-	return filtered
+	return geo.Direction(head, bestCoord)
 }
 
 func calculateFutureBoards(state *types.GameState, n int) [][][]float64 {
+	geo := rules.GeometryOf(state)
 	futureBoards := make([][][]float64, n)
 
 	for i := 0; i < n; i++ {
 		futureState := state.Copy()
 		for j, snake := range futureState.Board.Snakes {
 			futureState.Board.Snakes[j].Body = snake.Body[:len(snake.Body)-1]
-			for _, nhead := range getAdjacentCoords(snake.Head) {
-				if isCoordInBounds(nhead, state.Board.Width, state.Board.Height) {
-					futureState.Board.Snakes[j].Body = append(futureState.Board.Snakes[j].Body, nhead) // does not matter if it's added in the end
-				}
+			for _, nhead := range geo.Neighbors(snake.Head) {
+				futureState.Board.Snakes[j].Body = append(futureState.Board.Snakes[j].Body, nhead) // does not matter if it's added in the end
 			}
 		}
 		futureBoards[i] = createBoard(futureState)
@@ -176,16 +114,34 @@ func averageBoards(boards [][][]float64) [][]float64 {
 	return averagedBoard
 }
 
+// Number of possible future boards averaged
+var FutureBoards = 4
+
 func Move(state *types.GameState) types.BattlesnakeMoveResponse {
-	N := 4 // Number of possible future boards
+	return MoveContext(context.Background(), state)
+}
+
+// MoveContext averages FutureBoards future boards, or as many as the
+// futureBoards param says
+func MoveContext(ctx context.Context, state *types.GameState) types.BattlesnakeMoveResponse {
+	N := strategy.ParamsFrom(ctx).Int("futureBoards", FutureBoards)
+	if N < 1 {
+		N = 1
+	}
 	futureBoards := calculateFutureBoards(state, N)
 	averagedBoard := averageBoards(futureBoards)
 
 	me := state.You
 	head := me.Head
-	move := nextMove(head, averagedBoard)
+	geo := rules.GeometryOf(state)
+	move := nextMove(geo, head, averagedBoard)
 
-	return types.BattlesnakeMoveResponse{
-		Move: move,
+	response := types.BattlesnakeMoveResponse{
+		Move: move.String(),
+	}
+	if next := geo.Move(head, move); types.NewIndex(state).IsFood(next) {
+		response.Shout = shout.Food(next)
 	}
+
+	return response
 }
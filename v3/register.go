@@ -0,0 +1,21 @@
+package v3
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+const author = "samyfodil"
+
+func init() {
+	strategy.Register(strategy.Func{
+		StrategyName: "v3",
+		SearchFunc:   MoveContext,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#f1c40f",
+			Head:   "sand-worm",
+			Tail:   "freckled",
+		},
+	}, "tau007")
+}
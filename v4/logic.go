@@ -1,36 +1,20 @@
 package v4
 
 import (
+	"context"
 	"math/rand"
 	"sort"
-	"time"
 
+	"github.com/samyfodil/tb_library_snake_001/rng"
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/search"
+	"github.com/samyfodil/tb_library_snake_001/shout"
+	"github.com/samyfodil/tb_library_snake_001/strategy"
 	"github.com/samyfodil/tb_library_snake_001/types"
 )
 
 var LookStepsAhead = 16
 
-// Initialize the random seed
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
-// Helper functions
-
-func applyMove(head types.Coord, move string) types.Coord {
-	switch move {
-	case "up":
-		head.Y++
-	case "down":
-		head.Y--
-	case "left":
-		head.X--
-	case "right":
-		head.X++
-	}
-	return head
-}
-
 // Main logic
 
 func isCoordInList(coord types.Coord, list []types.Coord) bool {
@@ -42,21 +26,30 @@ func isCoordInList(coord types.Coord, list []types.Coord) bool {
 	return false
 }
 
-func predictSnakesNextPositions(state *types.GameState) types.Board {
+func predictSnakesNextPositions(state *types.GameState, ix *types.Index, r *rand.Rand) types.Board {
+	geo := rules.GeometryOf(state)
 	board := state.Board
+
+	// The snakes of state are shared with the states before it, and ix
+	// indexes them as they are before anyone moves
+	board.Snakes = append([]types.Battlesnake(nil), state.Board.Snakes...)
 	for i, snake := range board.Snakes {
 		// Skip dead snakes
-		if snake.Health <= 1 || (isCoordInList(snake.Body[0], state.Board.Hazards) && snake.Health <= 16) {
+		if snake.Health <= 1 || (ix.IsHazard(snake.Body[0]) && snake.Health <= 16) {
 			continue
 		}
 
-		safeMoves := getSafeMoves(state, snake.Head, snake.Body)
+		safeMoves := getSafeMoves(state, ix, snake.Head, snake.Body)
 		if len(safeMoves) > 0 {
-			move := safeMoves[rand.Intn(len(safeMoves))]
-			newHead := applyMove(snake.Head, move)
+			move := safeMoves[r.Intn(len(safeMoves))]
+			newHead := geo.Move(snake.Head, move)
 			board.Snakes[i].Body = append([]types.Coord{newHead}, snake.Body[:len(snake.Body)-1]...)
 
-			if isCoordInList(newHead, board.Food) {
+			if !rules.TailMoves(state) {
+				// Constrictor snakes grow every turn and never starve
+				board.Snakes[i].Body = append([]types.Coord{newHead}, snake.Body...)
+				board.Snakes[i].Health = rules.MaxHealth
+			} else if ix.IsFood(newHead) {
 				board.Snakes[i].Body = append([]types.Coord{newHead}, snake.Body...)
 				board.Snakes[i].Health = 100
 			} else {
@@ -70,19 +63,21 @@ func predictSnakesNextPositions(state *types.GameState) types.Board {
 	return board
 }
 
-func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord) []string {
-	board := state.Board
+func getSafeMoves(state *types.GameState, ix *types.Index, head types.Coord, body []types.Coord) []types.Direction {
+	geo := rules.GeometryOf(state)
 
 	// Initialize move scores
-	moveScores := make(map[string]int)
+	moveScores := make(map[types.Direction]int)
 
-	isHeadInHazard := isCoordInList(head, board.Hazards)
+	// In royale games cells about to turn into hazards count as hazards
+	forecast := rules.ForecastHazards(state)
+	isHeadInHazard := ix.IsHazard(head)
 
 	for _, move := range possibleMoves {
-		newHead := applyMove(head, move)
+		newHead := geo.Move(head, move)
 
 		// Check if the new head position is out of the board
-		if newHead.X < 0 || newHead.X >= board.Width || newHead.Y < 0 || newHead.Y >= board.Height {
+		if !geo.InBounds(newHead) {
 			moveScores[move] = -1000
 			continue
 		}
@@ -94,7 +89,7 @@ func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord)
 		}
 
 		// Check if the new head position is in another snake's body
-		if isCoordInSnakeLists(state, newHead) {
+		if isCoordInSnakeLists(state, ix, newHead) {
 			moveScores[move] = -1000
 			continue
 		}
@@ -105,7 +100,7 @@ func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord)
 				continue
 			}
 			for _, otherMove := range possibleMoves {
-				otherNewHead := applyMove(otherSnake.Head, otherMove)
+				otherNewHead := geo.Move(otherSnake.Head, otherMove)
 				if newHead == otherNewHead {
 					if len(otherSnake.Body) >= len(body) {
 						moveScores[move] = -1000
@@ -116,7 +111,7 @@ func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord)
 		}
 
 		// Check if the new head position is in a hazard
-		if isCoordInList(newHead, board.Hazards) {
+		if ix.IsHazard(newHead) || forecast.MayBeHazard(newHead, state.Turn+1) {
 			if !isHeadInHazard {
 				moveScores[move] = -500
 			} else {
@@ -130,9 +125,10 @@ func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord)
 	}
 
 	// Find the best move based on scores
-	bestMove := ""
+	bestMove := types.NoDirection
 	bestScore := -1001
-	for move, score := range moveScores {
+	for _, move := range possibleMoves {
+		score := moveScores[move]
 		if score > bestScore {
 			bestScore = score
 			bestMove = move
@@ -141,48 +137,48 @@ func getSafeMoves(state *types.GameState, head types.Coord, body []types.Coord)
 
 	// If there's no best move, return an empty slice
 	if bestScore <= -1000 {
-		return []string{}
+		return []types.Direction{}
 	}
 
-	return []string{bestMove}
+	return []types.Direction{bestMove}
 }
 
-func isCoordInSnakeLists(state *types.GameState, coord types.Coord) bool {
-	for _, snake := range state.Board.Snakes {
-		// Skip the dead snakes
-		if snake.Health <= 1 || (isCoordInList(snake.Body[0], state.Board.Hazards) && snake.Health <= 16) {
-			continue
-		}
+func isCoordInSnakeLists(state *types.GameState, ix *types.Index, coord types.Coord) bool {
+	// Skip the dead snakes
+	dead := func(snake types.Battlesnake) bool {
+		return snake.Health <= 1 || (ix.IsHazard(snake.Body[0]) && snake.Health <= 16)
+	}
+
+	switch ix.Occupants(coord) {
+	case 0:
+		return false
+	case 1:
+		snake, _ := ix.Occupant(coord)
+		return !dead(state.Board.Snakes[snake])
+	}
 
-		if isCoordInList(coord, snake.Body) {
+	// Predicted snakes can overlap, a dead one over one that is not
+	for _, snake := range state.Board.Snakes {
+		if !dead(snake) && isCoordInList(coord, snake.Body) {
 			return true
 		}
 	}
 	return false
 }
 
-func distance(a, b types.Coord) int {
-	return abs(a.X-b.X) + abs(a.Y-b.Y)
-}
-
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
-
-func shuffleMoves(moves []string, score []int) {
+func shuffleMoves(moves []types.Direction, score []int, r *rand.Rand) {
 	for i := len(moves) - 1; i > 0; i-- {
-		j := rand.Intn(i + 1)
+		j := r.Intn(i + 1)
 		moves[i], moves[j] = moves[j], moves[i]
 		score[i], score[j] = score[j], score[i]
 	}
 }
 
-func isSafeMove(newHead types.Coord, state *types.GameState) bool {
+func isSafeMove(newHead types.Coord, state *types.GameState, ix *types.Index) bool {
+	geo := rules.GeometryOf(state)
+
 	// Check if the new head position is within the board boundaries
-	if newHead.X < 0 || newHead.Y < 0 || newHead.X >= state.Board.Width || newHead.Y >= state.Board.Height {
+	if !geo.InBounds(newHead) {
 		return false
 	}
 
@@ -192,28 +188,17 @@ func isSafeMove(newHead types.Coord, state *types.GameState) bool {
 	}
 
 	// Check if the new head position collides with other snakes
-	for _, snake := range state.Board.Snakes {
-		// Skip the dead snakes
-		if snake.Health <= 1 || (isCoordInList(snake.Body[0], state.Board.Hazards) && snake.Health <= 16) {
-			continue
-		}
-
-		if isCoordInList(newHead, snake.Body) {
-			return false
-		}
+	if isCoordInSnakeLists(state, ix, newHead) {
+		return false
 	}
 
 	return true
 }
 
-func countSegmentsInHazard(snake types.Battlesnake, board types.Board) int {
+func countSegmentsInHazard(snake types.Battlesnake, ix *types.Index) int {
 	segmentsInHazard := 0
 	for _, segment := range snake.Body {
-		for _, hazard := range board.Hazards {
-			if segment.X == hazard.X && segment.Y == hazard.Y {
-				segmentsInHazard++
-			}
-		}
+		segmentsInHazard += ix.Hazards(segment)
 	}
 	return segmentsInHazard
 }
@@ -230,18 +215,19 @@ func freeSpaceRatio(state *types.GameState) float64 {
 	return float64(freeSpaces) / float64(totalSpaces)
 }
 
-var possibleMoves = []string{"up", "down", "left", "right"}
+var possibleMoves = types.Directions()
 
-func chooseBestMove(state *types.GameState, safeMoves []string, safeMovesAfterNStep []int) string {
+func chooseBestMove(state *types.GameState, ix *types.Index, safeMoves []types.Direction, safeMovesAfterNStep []int, r *rand.Rand) types.Direction {
+	geo := rules.GeometryOf(state)
 	myHead := state.You.Head
 	minDist := state.Board.Width*state.Board.Height + 1
 	maxDist := -1
 
-	bestMoves := []string{}
+	bestMoves := []types.Direction{}
 	bestMovesScore := []int{}
 
 	// Calculate the number of snake body segments in the hazard area
-	segmentsInHazard := countSegmentsInHazard(state.You, state.Board)
+	segmentsInHazard := countSegmentsInHazard(state.You, ix)
 	hazardWeight := float64(segmentsInHazard) * 1.5 // Adjust the multiplier as needed to fine-tune the prioritization
 
 	// Calculate the free space ratio
@@ -267,10 +253,10 @@ func chooseBestMove(state *types.GameState, safeMoves []string, safeMovesAfterNS
 	}
 
 	for i, move := range safeMoves {
-		newHead := applyMove(myHead, move)
+		newHead := geo.Move(myHead, move)
 
 		// Check if the new head position is in a hazard
-		inHazard := isCoordInList(newHead, state.Board.Hazards)
+		inHazard := ix.IsHazard(newHead)
 
 		// Move out of the hazard area when more of the snake's body is in it
 		if inHazard {
@@ -286,7 +272,7 @@ func chooseBestMove(state *types.GameState, safeMoves []string, safeMovesAfterNS
 				continue
 			}
 			for _, otherMove := range possibleMoves {
-				otherNewHead := applyMove(otherSnake.Head, otherMove)
+				otherNewHead := geo.Move(otherSnake.Head, otherMove)
 				if newHead == otherNewHead {
 					// If the snake is smaller or equal in size to the other snake, avoid the move
 					if len(state.You.Body) <= len(otherSnake.Body) {
@@ -298,7 +284,7 @@ func chooseBestMove(state *types.GameState, safeMoves []string, safeMovesAfterNS
 
 		// Keep the original logic for choosing the best move based on distance to food, but prioritize based on health
 		for _, food := range state.Board.Food {
-			dist := distance(newHead, food)
+			dist := geo.Distance(newHead, food)
 			if shouldGetFood {
 				// If the snake should get food, prioritize the moves that minimize the distance to food
 				if dist <= minDist {
@@ -319,7 +305,7 @@ func chooseBestMove(state *types.GameState, safeMoves []string, safeMovesAfterNS
 	}
 
 	// Shuffle the best moves list
-	shuffleMoves(bestMoves, bestMovesScore)
+	shuffleMoves(bestMoves, bestMovesScore, r)
 
 	sort.Slice(bestMoves, func(i, j int) bool {
 		return bestMovesScore[i] > bestMovesScore[j]
@@ -328,69 +314,161 @@ func chooseBestMove(state *types.GameState, safeMoves []string, safeMovesAfterNS
 
 	// Find the first safe move from the shuffled list
 	for _, move := range bestMoves {
-		newHead := applyMove(state.You.Head, move)
+		newHead := geo.Move(state.You.Head, move)
 
-		if isSafeMove(newHead, state) {
+		if isSafeMove(newHead, state, ix) {
 			return move
 		}
 	}
 
 	// If there are no safe moves, return a random one
-	return safeMoves[rand.Intn(len(safeMoves))]
+	return safeMoves[r.Intn(len(safeMoves))]
 }
 
-func isMoveSafeAfterNSteps(state *types.GameState, move string, steps int) (bool, int) {
+// isMoveSafeAfterNSteps returns how many of steps ahead move stays safe,
+// steps when it is safe all the way. ix indexes the board of state and hash
+// is its search.Hash.
+func isMoveSafeAfterNSteps(ctx context.Context, state *types.GameState, ix *types.Index, hash uint64, move types.Direction, steps int, r *rand.Rand, tt *search.Table) int {
 	if steps == 0 {
-		return true, steps
+		return 0
+	}
+
+	// Out of time, the caller drops whatever this returns
+	if ctx.Err() != nil {
+		return 0
+	}
+
+	// The same move from the same position may have been looked at on
+	// another line already. A look ahead that went as deep says how far it
+	// goes, one that ran into a dead end says where, however deep it went.
+	key := hash ^ search.MoveKey(move)
+	if e, ok := tt.Probe(key); ok {
+		if e.Depth >= steps {
+			if e.Score < steps {
+				return e.Score
+			}
+			return steps
+		}
+		if e.Score < e.Depth {
+			return e.Score
+		}
 	}
 
+	safe := isMoveSafe(ctx, state, ix, hash, move, steps, r, tt)
+
+	// A look ahead cut short proves nothing
+	if ctx.Err() == nil {
+		tt.Store(search.Entry{Hash: key, Depth: steps, Score: safe, Move: move})
+	}
+
+	return safe
+}
+
+// isMoveSafe plays move and returns how many of steps ahead it stays safe
+func isMoveSafe(ctx context.Context, state *types.GameState, ix *types.Index, hash uint64, move types.Direction, steps int, r *rand.Rand, tt *search.Table) int {
+	geo := rules.GeometryOf(state)
+
 	// Apply the move to the current head position
-	newHead := applyMove(state.You.Head, move)
+	newHead := geo.Move(state.You.Head, move)
 
 	// Check if the new head position is inside the board
-	if newHead.X < 0 || newHead.X >= state.Board.Width || newHead.Y < 0 || newHead.Y >= state.Board.Height {
-		return false, steps
+	if !geo.InBounds(newHead) {
+		return 0
 	}
 
-	// Create a new state where our snake has made the move
-	newBody := append([]types.Coord{newHead}, state.You.Body[:len(state.You.Body)-1]...)
-	newState := state
+	// Create a new state where our snake has made the move, the tail stays
+	// in place when the ruleset does not move it
+	keep := len(state.You.Body) - 1
+	if !rules.TailMoves(state) {
+		keep = len(state.You.Body)
+	}
+	newBody := append([]types.Coord{newHead}, state.You.Body[:keep]...)
+	next := *state
+	newState := &next
+	newState.Turn++
 	newState.You.Body = newBody
 	newState.You.Head = newHead
 
-	// Predict the next positions of all snakes, including our own
-	newState.Board = predictSnakesNextPositions(newState)
+	// Hazards the royale shrink may have added by now are as real as the
+	// ones already on the board
+	if forecast := rules.ForecastHazards(state); forecast.NextShrink() == newState.Turn {
+		newState.Board.Hazards = forecast.Hazards(newState.Turn)
+		ix = types.NewIndex(newState)
+	}
+
+	// Predict the next positions of all snakes, including our own. The
+	// index of the board they end up on is the one the next step starts
+	// from.
+	newState.Board = predictSnakesNextPositions(newState, ix, r)
+	nextIx := types.NewIndex(newState)
+	nextHash := search.Rehash(hash, state, newState)
 
 	// Get the safe moves for the new state
-	safeMoves := getSafeMoves(newState, newState.You.Head, newState.You.Body)
+	safeMoves := getSafeMoves(newState, nextIx, newState.You.Head, newState.You.Body)
 
 	// If there are no safe moves left in the new state, the initial move is not safe
 	if len(safeMoves) == 0 {
-		return false, steps
+		return 0
 	}
 
-	// Check if the moves are safe after N-1 steps
+	// The move is as safe as the least safe of the moves after it
+	safe := steps
 	for _, nextMove := range safeMoves {
-		if safe, _ := isMoveSafeAfterNSteps(newState, nextMove, steps-1); !safe {
-			return false, steps
+		if after := 1 + isMoveSafeAfterNSteps(ctx, newState, nextIx, nextHash, nextMove, steps-1, r, tt); after < safe {
+			safe = after
 		}
 	}
 
-	return true, steps
+	return safe
 }
 
 func Move(state *types.GameState) types.BattlesnakeMoveResponse {
+	return MoveContext(context.Background(), state)
+}
+
+// MoveContext looks one more step ahead at a time, up to LookStepsAhead or
+// the lookStepsAhead param, and goes with the deepest look ahead finished
+// before the context is done
+func MoveContext(ctx context.Context, state *types.GameState) types.BattlesnakeMoveResponse {
+	depth := strategy.ParamsFrom(ctx).Int("lookStepsAhead", LookStepsAhead)
+	if depth < 1 {
+		depth = 1
+	}
+
 	// Get safe moves for our snake based on the current state
-	safeMoves := getSafeMoves(state, state.You.Head, state.You.Body)
+	ix := types.NewIndex(state)
+	safeMoves := getSafeMoves(state, ix, state.You.Head, state.You.Body)
+
+	// Filter out moves that would not be safe after N steps, remembering
+	// what was found from one depth to the next
+	tt := search.AcquireTable()
+	defer tt.Release()
+	hash := search.Hash(state)
+
+	var safeMovesAfterNSteps []int
+	lookedAhead := 0
+	for steps := 1; steps <= depth; steps++ {
+		r := rng.New(state)
+
+		scores := make([]int, len(safeMoves))
+		for i, move := range safeMoves {
+			scores[i] = isMoveSafeAfterNSteps(ctx, state, ix, hash, move, steps, r, tt)
+		}
 
-	// Filter out moves that would not be safe after N steps
-	safeMovesAfterNSteps := make([]int, len(safeMoves))
-	for i, move := range safeMoves {
-		_, safeMovesAfterNSteps[i] = isMoveSafeAfterNSteps(state, move, LookStepsAhead)
+		// A look ahead cut short is only better than nothing
+		if ctx.Err() != nil && safeMovesAfterNSteps != nil {
+			break
+		}
+		safeMovesAfterNSteps = scores
+		lookedAhead = steps
+
+		if ctx.Err() != nil {
+			break
+		}
 	}
 
 	// Choose the best move based on your criteria (e.g., move towards food)
-	nextMove := chooseBestMove(state, safeMoves, safeMovesAfterNSteps)
+	nextMove := chooseBestMove(state, ix, safeMoves, safeMovesAfterNSteps, rng.New(state))
 
-	return types.BattlesnakeMoveResponse{Move: nextMove}
+	return types.BattlesnakeMoveResponse{Move: nextMove.String(), Shout: shout.LookedAhead(lookedAhead)}
 }
@@ -0,0 +1,33 @@
+package v4
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+func TestMoveContextDepth(t *testing.T) {
+	us := types.Battlesnake{
+		ID:     "us",
+		Health: 90,
+		Body:   []types.Coord{{X: 3, Y: 3}, {X: 3, Y: 2}, {X: 3, Y: 1}},
+		Head:   types.Coord{X: 3, Y: 3},
+		Length: 3,
+	}
+	state := &types.GameState{
+		Game:  types.Game{ID: "depth", Ruleset: types.Ruleset{Name: "standard"}},
+		Board: types.Board{Width: 7, Height: 7, Snakes: []types.Battlesnake{us}, Food: []types.Coord{{X: 5, Y: 5}}},
+		You:   us,
+	}
+
+	// Any depth a config can give, a typo included, comes up with a move
+	for _, depth := range []float64{-3, 0, 1, 4} {
+		ctx := strategy.WithParams(context.Background(), strategy.Params{"lookStepsAhead": depth})
+		response := MoveContext(ctx, state)
+		if _, err := types.ParseDirection(response.Move); err != nil {
+			t.Errorf("depth %v: moved %q", depth, response.Move)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package v4
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+const author = "samyfodil"
+
+func init() {
+	strategy.Register(strategy.Func{
+		StrategyName: "v4",
+		SearchFunc:   MoveContext,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#2c3e50",
+			Head:   "shades",
+			Tail:   "fat-rattle",
+		},
+	}, "tau008")
+}
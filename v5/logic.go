@@ -0,0 +1,137 @@
+package v5
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/shout"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+var possibleMoves = types.Directions()
+
+// Helper functions
+
+func occupiedCells(state *types.GameState) map[types.Coord]bool {
+	occupied := make(map[types.Coord]bool)
+	for _, snake := range state.Board.Snakes {
+		for _, segment := range snake.Body {
+			occupied[segment] = true
+		}
+	}
+	for _, segment := range state.You.Body {
+		occupied[segment] = true
+	}
+	return occupied
+}
+
+// Main logic
+
+// contestedCells returns the cells an opponent at least as long as us could
+// move its head into this turn. Moving there risks losing a head-to-head.
+func contestedCells(geo rules.Geometry, state *types.GameState) map[types.Coord]bool {
+	contested := make(map[types.Coord]bool)
+	for _, snake := range state.Board.Snakes {
+		if snake.ID == state.You.ID || len(snake.Body) < len(state.You.Body) {
+			continue
+		}
+		for _, next := range geo.Neighbors(snake.Body[0]) {
+			contested[next] = true
+		}
+	}
+	return contested
+}
+
+// preyAt returns a shorter opponent that could move its head to coord, and
+// would lose the head-to-head there
+func preyAt(geo rules.Geometry, state *types.GameState, coord types.Coord) (types.Battlesnake, bool) {
+	for _, snake := range state.Board.Snakes {
+		if snake.ID == state.You.ID || len(snake.Body) >= len(state.You.Body) {
+			continue
+		}
+		for _, next := range geo.Neighbors(snake.Body[0]) {
+			if next == coord {
+				return snake, true
+			}
+		}
+	}
+	return types.Battlesnake{}, false
+}
+
+// spaceAfterMove is the number of cells still reachable once our head moves
+// to newHead. Opponent heads spread one cell in every direction since we do
+// not know where they will go.
+func spaceAfterMove(geo rules.Geometry, state *types.GameState, newHead types.Coord, occupied map[types.Coord]bool) int {
+	blocked := make(map[types.Coord]bool, len(occupied)+1)
+	for coord := range occupied {
+		blocked[coord] = true
+	}
+	blocked[newHead] = true
+
+	// In any other ruleset tails do move, so they are not walls
+	if rules.TailMoves(state) {
+		for _, snake := range state.Board.Snakes {
+			delete(blocked, snake.Body[len(snake.Body)-1])
+		}
+	}
+
+	for _, snake := range state.Board.Snakes {
+		if snake.ID == state.You.ID {
+			continue
+		}
+		for _, next := range geo.Neighbors(snake.Body[0]) {
+			if next != newHead {
+				blocked[next] = true
+			}
+		}
+	}
+
+	return geo.Reachable(newHead, func(coord types.Coord) bool {
+		return blocked[coord]
+	}, 0)
+}
+
+// Move is built for constrictor games: there is no food and every snake
+// leaves a permanent trail, so the only thing worth playing for is room to
+// move.
+func Move(state *types.GameState) types.BattlesnakeMoveResponse {
+	geo := rules.GeometryOf(state)
+	head := state.You.Body[0]
+	occupied := occupiedCells(state)
+	contested := contestedCells(geo, state)
+
+	bestMove := types.NoDirection
+	bestScore := -1
+
+	for _, move := range possibleMoves {
+		newHead := geo.Move(head, move)
+		if !geo.InBounds(newHead) || occupied[newHead] {
+			continue
+		}
+
+		score := spaceAfterMove(geo, state, newHead, occupied)
+
+		// A head-to-head we might lose is only worth it when every
+		// other option is a dead end
+		if contested[newHead] {
+			score /= 4
+		}
+
+		if score > bestScore {
+			bestScore = score
+			bestMove = move
+		}
+	}
+
+	// Every neighbor is taken, any move is as bad as the other
+	if !bestMove.Valid() {
+		return types.BattlesnakeMoveResponse{Move: types.Up.String(), Shout: shout.Trapped(0)}
+	}
+
+	response := types.BattlesnakeMoveResponse{Move: bestMove.String(), Shout: shout.Room(bestScore)}
+	if bestScore < len(state.You.Body) {
+		response.Shout = shout.Trapped(bestScore)
+	} else if prey, ok := preyAt(geo, state, geo.Move(head, bestMove)); ok {
+		response.Shout = shout.Kill(prey)
+	}
+
+	return response
+}
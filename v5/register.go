@@ -0,0 +1,21 @@
+package v5
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+const author = "samyfodil"
+
+func init() {
+	strategy.Register(strategy.Func{
+		StrategyName: "v5",
+		MoveFunc:     Move,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#d35400",
+			Head:   "tongue",
+			Tail:   "small-rattle",
+		},
+	}, "tau009")
+}
@@ -0,0 +1,207 @@
+package v6
+
+import (
+	"context"
+
+	"github.com/samyfodil/tb_library_snake_001/rules"
+	"github.com/samyfodil/tb_library_snake_001/shout"
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+var possibleMoves = types.Directions()
+
+// Below this much health we leave the cycle to eat, on top of the distance
+// to the food
+var HealthMargin = 10
+
+// Helper functions
+
+func isCoordInList(coord types.Coord, list []types.Coord) bool {
+	for _, c := range list {
+		if c == coord {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSolo reports whether we are alone on the board, either because the game
+// is a solo game or because every opponent is gone.
+func IsSolo(state *types.GameState) bool {
+	if state.Game.Ruleset.Name == rules.Solo {
+		return true
+	}
+
+	for _, snake := range state.Board.Snakes {
+		if snake.ID != state.You.ID {
+			return false
+		}
+	}
+	return true
+}
+
+// Main logic
+
+// cycleMove returns the move that follows a Hamiltonian cycle over the board.
+// Column 0 is kept as the way back down, the remaining columns are swept in a
+// boustrophedon going up. It only exists for boards with an even width or
+// height, and ok is false when head is not on it.
+func cycleMove(width, height int, head types.Coord) (move types.Direction, ok bool) {
+	if width < 2 || height < 2 || (width%2 != 0 && height%2 != 0) {
+		return types.NoDirection, false
+	}
+	if head.X < 0 || head.Y < 0 || head.X >= width || head.Y >= height {
+		return types.NoDirection, false
+	}
+
+	if height%2 != 0 {
+		// Sweep rows instead of columns by flipping the board on its diagonal
+		move, ok = cycleMove(height, width, types.Coord{X: head.Y, Y: head.X})
+		return transpose(move), ok
+	}
+
+	switch {
+	case head.X == 0:
+		if head.Y == 0 {
+			return types.Right, true
+		}
+		return types.Down, true
+	case head.Y%2 == 0:
+		// Even rows go right, then up at the last column
+		if head.X == width-1 {
+			return types.Up, true
+		}
+		return types.Right, true
+	default:
+		// Odd rows go left, then up at column 1, except the top row which
+		// heads for column 0 to close the cycle
+		if head.Y == height-1 {
+			return types.Left, true
+		}
+		if head.X == 1 {
+			return types.Up, true
+		}
+		return types.Left, true
+	}
+}
+
+// cycleWidth is the width of the board the cycle covers. A board with odd
+// sides has no such cycle, the last column is left out.
+func cycleWidth(width, height int) int {
+	if width%2 != 0 && height%2 != 0 {
+		return width - 1
+	}
+	return width
+}
+
+func transpose(move types.Direction) types.Direction {
+	switch move {
+	case types.Up:
+		return types.Right
+	case types.Down:
+		return types.Left
+	case types.Left:
+		return types.Down
+	case types.Right:
+		return types.Up
+	}
+	return move
+}
+
+func isSafe(geo rules.Geometry, state *types.GameState, coord types.Coord) bool {
+	if !geo.InBounds(coord) {
+		return false
+	}
+
+	// The tail moves out of the way unless we just ate
+	body := state.You.Body
+	if rules.TailMoves(state) && len(body) > 1 && body[len(body)-1] != body[len(body)-2] {
+		body = body[:len(body)-1]
+	}
+
+	return !isCoordInList(coord, body)
+}
+
+// spaceAfter counts how much room is left after moving the head to coord,
+// with the tail freed since it moves along with us.
+func spaceAfter(geo rules.Geometry, state *types.GameState, coord types.Coord) int {
+	body := state.You.Body[:len(state.You.Body)-1]
+	return geo.Reachable(coord, func(c types.Coord) bool {
+		return isCoordInList(c, body)
+	}, len(state.You.Body))
+}
+
+// towards returns a safe move that brings us closer to target without
+// walking into a pocket too small to hold our body.
+func towards(geo rules.Geometry, state *types.GameState, target types.Coord) types.Direction {
+	head := state.You.Body[0]
+	best := types.NoDirection
+	bestDist := geo.Width + geo.Height + 1
+
+	for _, move := range possibleMoves {
+		next := geo.Move(head, move)
+		if !isSafe(geo, state, next) || spaceAfter(geo, state, next) < len(state.You.Body)-1 {
+			continue
+		}
+		if dist := geo.Distance(next, target); dist < bestDist {
+			bestDist = dist
+			best = move
+		}
+	}
+
+	return best
+}
+
+func closestFood(geo rules.Geometry, state *types.GameState) (types.Coord, int) {
+	head := state.You.Body[0]
+	closest := types.Coord{}
+	minDist := -1
+
+	for _, food := range state.Board.Food {
+		if dist := geo.Distance(head, food); minDist < 0 || dist < minDist {
+			minDist = dist
+			closest = food
+		}
+	}
+
+	return closest, minDist
+}
+
+func Move(state *types.GameState) types.BattlesnakeMoveResponse {
+	return MoveContext(context.Background(), state)
+}
+
+// MoveContext keeps the snake on a cycle covering the whole board, where it
+// can never trap itself, and only leaves it to eat when health requires. The
+// healthMargin param replaces HealthMargin.
+func MoveContext(ctx context.Context, state *types.GameState) types.BattlesnakeMoveResponse {
+	geo := rules.GeometryOf(state)
+	head := state.You.Body[0]
+	margin := strategy.ParamsFrom(ctx).Int("healthMargin", HealthMargin)
+
+	food, dist := closestFood(geo, state)
+	if dist >= 0 && state.You.Health <= dist+margin {
+		if move := towards(geo, state, food); move.Valid() {
+			return types.BattlesnakeMoveResponse{Move: move.String(), Shout: shout.Food(food)}
+		}
+	}
+
+	if move, ok := cycleMove(cycleWidth(geo.Width, geo.Height), geo.Height, head); ok && isSafe(geo, state, geo.Move(head, move)) {
+		return types.BattlesnakeMoveResponse{Move: move.String(), Shout: shout.Cycle()}
+	}
+
+	// Off the cycle, after eating or on an odd sized board: chase our own
+	// tail, which always leaves a way out
+	if move := towards(geo, state, state.You.Body[len(state.You.Body)-1]); move.Valid() {
+		return types.BattlesnakeMoveResponse{Move: move.String(), Shout: shout.Tail()}
+	}
+
+	for _, move := range possibleMoves {
+		if next := geo.Move(head, move); isSafe(geo, state, next) {
+			return types.BattlesnakeMoveResponse{Move: move.String(), Shout: shout.Trapped(spaceAfter(geo, state, next))}
+		}
+	}
+
+	return types.BattlesnakeMoveResponse{Move: types.Up.String()}
+}
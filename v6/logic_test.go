@@ -0,0 +1,70 @@
+package v6
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+func TestCycleMove(t *testing.T) {
+	sizes := [][2]int{
+		{2, 2}, {4, 4}, {11, 12}, {12, 11}, // Even height, or odd height swept by rows
+		{2, 3}, {3, 2}, {6, 5}, {5, 6},
+		{3, 3}, {7, 5}, {11, 11}, {19, 19}, {25, 25}, // Odd sides, the last column left out
+	}
+
+	for _, size := range sizes {
+		board, height := size[0], size[1]
+		t.Run(fmt.Sprintf("%dx%d", board, height), func(t *testing.T) {
+			width := cycleWidth(board, height)
+			if width%2 != 0 && height%2 != 0 {
+				t.Fatalf("cycle width %d on a board %d high", width, height)
+			}
+
+			visited := make(map[types.Coord]bool)
+			head := types.Coord{}
+			for step := 0; step < width*height; step++ {
+				if visited[head] {
+					t.Fatalf("step %d: %v visited twice", step, head)
+				}
+				visited[head] = true
+
+				move, ok := cycleMove(width, height, head)
+				if !ok {
+					t.Fatalf("step %d: %v off the cycle", step, head)
+				}
+				head = move.Apply(head)
+				if head.X < 0 || head.Y < 0 || head.X >= width || head.Y >= height {
+					t.Fatalf("step %d: moved %s off the cycle to %v", step, move, head)
+				}
+			}
+
+			if len(visited) != width*height {
+				t.Errorf("visited %d cells, want %d", len(visited), width*height)
+			}
+			if head != (types.Coord{}) {
+				t.Errorf("ended on %v, want back on (0,0)", head)
+			}
+		})
+	}
+}
+
+func TestCycleMoveNoCycle(t *testing.T) {
+	tests := []struct {
+		width, height int
+		head          types.Coord
+	}{
+		{width: 3, height: 3, head: types.Coord{X: 0, Y: 0}},
+		{width: 1, height: 4, head: types.Coord{X: 0, Y: 0}},
+		{width: 4, height: 1, head: types.Coord{X: 0, Y: 0}},
+		{width: 4, height: 4, head: types.Coord{X: 4, Y: 0}},
+		{width: 4, height: 4, head: types.Coord{X: 0, Y: -1}},
+	}
+
+	for _, test := range tests {
+		if move, ok := cycleMove(test.width, test.height, test.head); ok {
+			t.Errorf("%dx%d at %v: moved %s, want no cycle", test.width, test.height, test.head, move)
+		}
+	}
+}
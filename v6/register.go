@@ -0,0 +1,21 @@
+package v6
+
+import (
+	"github.com/samyfodil/tb_library_snake_001/strategy"
+	"github.com/samyfodil/tb_library_snake_001/types"
+)
+
+const author = "samyfodil"
+
+func init() {
+	strategy.Register(strategy.Func{
+		StrategyName: "v6",
+		SearchFunc:   MoveContext,
+		About: types.BattlesnakeInfoResponse{
+			Author: author,
+			Color:  "#1abc9c",
+			Head:   "silly",
+			Tail:   "block-bum",
+		},
+	}, "solo")
+}